@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var BuildConfigFile string
+
+// BuildConfig holds the manual build commands of compiled languages, for all repositories and for single ones.
+type BuildConfig struct {
+	Languages    map[string]string            `yaml:"languages"`
+	Repositories map[string]map[string]string `yaml:"repositories"`
+}
+
+var buildConfig BuildConfig
+
+// csvBuildCommands holds the build commands of the second column of the csv file, by repository.
+var csvBuildCommands = map[string]string{}
+
+// compiledLanguages are the CodeQL languages that can be built manually.
+var compiledLanguages = map[string]bool{"c-cpp": true, "csharp": true, "go": true, "java-kotlin": true, "swift": true}
+
+var languageAliases = map[string]string{"c": "c-cpp", "cpp": "c-cpp", "c++": "c-cpp", "c#": "csharp", "java": "java-kotlin", "kotlin": "java-kotlin"}
+
+const manualBuildStep = "${{ matrix.build-command }}"
+
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := languageAliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+func loadBuildConfig(BuildConfigFile string) (BuildConfig, error) {
+	config := BuildConfig{Languages: map[string]string{}, Repositories: map[string]map[string]string{}}
+	if len(BuildConfigFile) <= 0 {
+		return config, nil
+	}
+
+	content, err := os.ReadFile(BuildConfigFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to read build config file %s\n", BuildConfigFile)
+		return config, err
+	}
+
+	var parsed BuildConfig
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		log.Printf("ERROR: Unable to parse build config file %s\n", BuildConfigFile)
+		return config, err
+	}
+
+	// keys are matched case insensitively and languages by their CodeQL name
+	for language, command := range parsed.Languages {
+		config.Languages[normalizeLanguage(language)] = command
+	}
+	for repository, commands := range parsed.Repositories {
+		normalized := map[string]string{}
+		for language, command := range commands {
+			normalized[normalizeLanguage(language)] = command
+		}
+		config.Repositories[strings.ToLower(repository)] = normalized
+	}
+	return config, nil
+}
+
+// loadCsvBuildCommands reads the build commands of the second column of the csv file, by repository.
+func loadCsvBuildCommands(CsvFile string) (map[string]string, error) {
+	commands := map[string]string{}
+	if len(CsvFile) <= 0 {
+		return commands, nil
+	}
+
+	csvFile, err := os.Open(CsvFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to open csv file %s\n", CsvFile)
+		return commands, err
+	}
+	defer csvFile.Close()
+
+	csvr := csv.NewReader(csvFile)
+	csvr.FieldsPerRecord = -1
+	for {
+		row, err := csvr.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Printf("ERROR: Unable to read csv file %s\n", CsvFile)
+			return commands, err
+		}
+		if len(row) > 1 && len(strings.TrimSpace(row[1])) > 0 {
+			commands[strings.ToLower(row[0])] = row[1]
+		}
+	}
+	return commands, nil
+}
+
+// loadBuildCommands loads the build config and the build commands of the csv file into buildConfig and csvBuildCommands.
+func loadBuildCommands(BuildConfigFile string, CsvFile string) error {
+	var err error
+	if buildConfig, err = loadBuildConfig(BuildConfigFile); err != nil {
+		return err
+	}
+	if csvBuildCommands, err = loadCsvBuildCommands(CsvFile); err != nil {
+		return err
+	}
+
+	// the merge keeps the matrix and steps of the existing workflow file, which would drop the build commands
+	if MergeWorkflow && (len(BuildConfigFile) > 0 || len(csvBuildCommands) > 0) {
+		log.Println("ERROR: The merge flag cannot be used with build commands from the build-config flag or the csv file")
+		return errors.New("the merge flag cannot be used with build commands")
+	}
+	return nil
+}
+
+// compiledLanguagesOf returns the CodeQL names of the compiled languages among the languages of a repository.
+func compiledLanguagesOf(languages []string) []string {
+	var compiled []string
+	seen := map[string]bool{}
+	for _, language := range languages {
+		language = normalizeLanguage(language)
+		if compiledLanguages[language] && !seen[language] {
+			seen[language] = true
+			compiled = append(compiled, language)
+		}
+	}
+	return compiled
+}
+
+// buildCommand returns the build command of the language in the repository with the languages.
+// The csv file comes first, then the repository in the build config and then the language in the build config.
+// The command of the csv file has no language, so it is only used when the repository has a single compiled language.
+func (repo *Repository) buildCommand(language string, languages []string) string {
+	language = normalizeLanguage(language)
+	if !compiledLanguages[language] {
+		return ""
+	}
+	if command, ok := csvBuildCommands[strings.ToLower(repo.FullName)]; ok {
+		if compiled := compiledLanguagesOf(languages); len(compiled) == 1 && compiled[0] == language {
+			return command
+		}
+	}
+	if command, ok := buildConfig.Repositories[strings.ToLower(repo.FullName)][language]; ok {
+		return command
+	}
+	return buildConfig.Languages[language]
+}
+
+// lineEdit holds the lines inserted before and after a line, and what the line is replaced with.
+type lineEdit struct {
+	before  []string
+	after   []string
+	replace []string
+	removed bool
+}
+
+// injectBuildSteps sets build-mode manual and the build command on each matrix include entry of a compiled language with a build command.
+// The manual build step runs the build command of the entry, and is added after the CodeQL init step when the workflow has none.
+// Only the lines that change are edited, so comments and formatting are kept.
+// It fails when the repository has build commands and none of them can be added, such as when the job calls a reusable workflow.
+func (repo *Repository) injectBuildSteps(workflowFile []byte, languages []string) ([]byte, error) {
+	compiled := compiledLanguagesOf(languages)
+	if _, ok := csvBuildCommands[strings.ToLower(repo.FullName)]; ok && len(compiled) > 1 {
+		log.Printf("ERROR: The build command of the csv file cannot be used for repository %s, it has several compiled languages: %s\n", repo.FullName, strings.Join(compiled, ", "))
+		return nil, fmt.Errorf("the build command of the csv file is ambiguous for the languages %s, set them under repositories in the build config", strings.Join(compiled, ", "))
+	}
+	var configured []string
+	for _, language := range compiled {
+		if len(repo.buildCommand(language, languages)) > 0 {
+			configured = append(configured, language)
+		}
+	}
+
+	root, err := parseWorkflowDocument(workflowFile)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(workflowFile), "\n")
+	edits := map[int]*lineEdit{}
+	edit := func(line int) *lineEdit {
+		if edits[line] == nil {
+			edits[line] = &lineEdit{}
+		}
+		return edits[line]
+	}
+
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return workflowFile, nil
+	}
+	injectedLanguages := map[string]bool{}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		job := jobs.Content[i].Value
+		block, ok := findYamlBlock(root, lines, "jobs", job, "strategy", "matrix", "include")
+		if !ok || block.value.Kind != yaml.SequenceNode {
+			if mappingValue(jobs.Content[i+1], "steps") != nil {
+				log.Printf("WARN: Job %s of the workflow for repository %s has no matrix include entries, no build commands were added\n", job, repo.FullName)
+			}
+			continue
+		}
+
+		injected := false
+		for _, entry := range block.value.Content {
+			language := mappingValue(entry, "language")
+			if language == nil || entry.Style&yaml.FlowStyle != 0 {
+				continue
+			}
+			command := repo.buildCommand(language.Value, languages)
+			if len(command) <= 0 {
+				continue
+			}
+			injected = true
+			injectedLanguages[normalizeLanguage(language.Value)] = true
+			log.Printf("Adding the build command of %s for repository %s\n", language.Value, repo.FullName)
+
+			indent := strings.Repeat(" ", entry.Content[0].Column-1)
+			quoted, _ := json.Marshal(command)
+			// new keys go after the language and build mode of the entry
+			anchor := language
+			if buildMode := mappingValue(entry, "build-mode"); buildMode != nil && buildMode.Line > anchor.Line {
+				anchor = buildMode
+			}
+			setEntryValue(lines, entry, anchor, "build-mode", indent+"build-mode: manual", edit)
+			setEntryValue(lines, entry, anchor, "build-command", indent+"build-command: "+string(quoted), edit)
+		}
+		if injected {
+			repo.injectManualBuildStep(root, lines, job, edit)
+		}
+	}
+
+	var missing []string
+	for _, language := range configured {
+		if !injectedLanguages[language] {
+			missing = append(missing, language)
+		}
+	}
+	if len(missing) > 0 && len(injectedLanguages) <= 0 {
+		log.Printf("ERROR: The build commands of %s could not be added to the workflow file for repository %s, no job has a matrix include entry for them\n", strings.Join(missing, ", "), repo.FullName)
+		return nil, fmt.Errorf("no matrix include entry for the build commands of %s", strings.Join(missing, ", "))
+	} else if len(missing) > 0 {
+		log.Printf("WARN: The build commands of %s were not added to the workflow file for repository %s, no job has a matrix include entry for them\n", strings.Join(missing, ", "), repo.FullName)
+	}
+
+	var result []string
+	for number, line := range lines {
+		lineEdit, ok := edits[number+1]
+		if !ok {
+			result = append(result, line)
+			continue
+		}
+		result = append(result, lineEdit.before...)
+		if lineEdit.replace != nil {
+			result = append(result, lineEdit.replace...)
+		} else if !lineEdit.removed {
+			result = append(result, line)
+		}
+		result = append(result, lineEdit.after...)
+	}
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// setEntryValue replaces the line of the key in the matrix entry, or adds the line after the anchor when the entry has no such key.
+func setEntryValue(lines []string, entry *yaml.Node, anchor *yaml.Node, key string, line string, edit func(int) *lineEdit) {
+	for i := 0; i+1 < len(entry.Content); i += 2 {
+		if entry.Content[i].Value != key {
+			continue
+		}
+		current := lines[entry.Content[i].Line-1]
+		// keep the dash of an entry that starts with the key
+		edit(entry.Content[i].Line).replace = []string{current[:entry.Content[i].Column-1] + strings.TrimLeft(line, " ")}
+		return
+	}
+	edit(anchor.Line).after = append(edit(anchor.Line).after, line)
+}
+
+// injectManualBuildStep points the manual build step of the job at the build command of the matrix entry, or adds one after the CodeQL init step.
+func (repo *Repository) injectManualBuildStep(root *yaml.Node, lines []string, job string, edit func(int) *lineEdit) {
+	block, ok := findYamlBlock(root, lines, "jobs", job, "steps")
+	if !ok || block.value.Kind != yaml.SequenceNode {
+		return
+	}
+	steps := block.value.Content
+
+	for i, step := range steps {
+		condition := mappingValue(step, "if")
+		if condition == nil || !strings.Contains(condition.Value, "build-mode") || !strings.Contains(condition.Value, "manual") {
+			continue
+		}
+		for k := 0; k+1 < len(step.Content); k += 2 {
+			if step.Content[k].Value != "run" {
+				continue
+			}
+			end := stepEnd(lines, steps, i, block.end)
+			if k+2 < len(step.Content) {
+				end = trimBlockEnd(lines, step.Content[k].Line, step.Content[k+2].Line-1)
+			}
+			run := step.Content[k]
+			edit(run.Line).replace = []string{lines[run.Line-1][:run.Column-1] + "run: " + manualBuildStep}
+			for line := run.Line + 1; line <= end; line++ {
+				edit(line).removed = true
+			}
+		}
+		return
+	}
+
+	for i, step := range steps {
+		uses := mappingValue(step, "uses")
+		if uses == nil || !strings.HasPrefix(uses.Value, "github/codeql-action/init@") {
+			continue
+		}
+
+		// the init step needs the build mode of the matrix entry
+		if with := mappingValue(step, "with"); with != nil && with.Kind == yaml.MappingNode && len(with.Content) > 0 && mappingValue(with, "build-mode") == nil {
+			first := with.Content[0]
+			edit(first.Line).before = append(edit(first.Line).before, strings.Repeat(" ", first.Column-1)+"build-mode: ${{ matrix.build-mode }}")
+		}
+
+		keyIndent := strings.Repeat(" ", step.Content[0].Column-1)
+		dashIndent := keyIndent
+		if dash := strings.LastIndex(lines[step.Line-1][:step.Content[0].Column-1], "-"); dash >= 0 {
+			dashIndent = strings.Repeat(" ", dash)
+		}
+		end := stepEnd(lines, steps, i, block.end)
+		edit(end).after = append(edit(end).after,
+			dashIndent+"- if: matrix.build-mode == 'manual'",
+			keyIndent+"name: Build",
+			keyIndent+"shell: bash",
+			keyIndent+"run: "+manualBuildStep,
+		)
+		return
+	}
+	log.Printf("WARN: Job %s of the workflow for repository %s has no CodeQL init step, no manual build step was added\n", job, repo.FullName)
+}
+
+// stepEnd returns the last line of the step, before the next step or the end of the steps.
+func stepEnd(lines []string, steps []*yaml.Node, i int, stepsEnd int) int {
+	end := stepsEnd
+	if i+1 < len(steps) {
+		end = steps[i+1].Line - 1
+	}
+	return trimBlockEnd(lines, steps[i].Line, end)
+}
+
+// trimBlockEnd moves the end of a block up past blank and comment lines.
+func trimBlockEnd(lines []string, start int, end int) int {
+	for end > start {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if len(trimmed) > 0 && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
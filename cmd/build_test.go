@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_loadBuildConfig(t *testing.T) {
+	config, err := loadBuildConfig("../examples/build-config.yml")
+	if err != nil {
+		t.Fatalf("loadBuildConfig() error = %v", err)
+	}
+	if got := config.Languages["java-kotlin"]; got != "./mvnw -B package -DskipTests" {
+		t.Errorf("loadBuildConfig() java-kotlin = %v", got)
+	}
+	if got := config.Repositories["ghas-rollout-test/legacy-service"]["csharp"]; got != "dotnet build LegacyService.sln" {
+		t.Errorf("loadBuildConfig() ghas-rollout-test/legacy-service csharp = %v", got)
+	}
+}
+
+func Test_loadCsvBuildCommands(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "repositories.csv")
+	if err := os.WriteFile(csvFile, []byte("paradisisland/maria,make all\nparadisisland/Rose\nparadisisland/sheena, \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadCsvBuildCommands(csvFile)
+	if err != nil {
+		t.Fatalf("loadCsvBuildCommands() error = %v", err)
+	}
+	if len(got) != 1 || got["paradisisland/maria"] != "make all" {
+		t.Errorf("loadCsvBuildCommands() = %v", got)
+	}
+}
+
+func TestRepository_buildCommand(t *testing.T) {
+	buildConfig = BuildConfig{
+		Languages:    map[string]string{"java-kotlin": "./mvnw -B package", "go": "make"},
+		Repositories: map[string]map[string]string{"paradisisland/rose": {"java-kotlin": "./gradlew assemble"}},
+	}
+	csvBuildCommands = map[string]string{"paradisisland/maria": "make all"}
+	defer func() {
+		buildConfig = BuildConfig{}
+		csvBuildCommands = map[string]string{}
+	}()
+
+	tests := []struct {
+		name      string
+		fullName  string
+		language  string
+		languages []string
+		want      string
+	}{
+		{name: "When the csv file has a build command", fullName: "paradisisland/maria", language: "java-kotlin", languages: []string{"Java", "Kotlin", "Python"}, want: "make all"},
+		{name: "When the csv file has a build command for several compiled languages", fullName: "paradisisland/maria", language: "java-kotlin", languages: []string{"Go", "Java"}, want: "./mvnw -B package"},
+		{name: "When the repository has a build command", fullName: "paradisisland/Rose", language: "java", languages: []string{"Java"}, want: "./gradlew assemble"},
+		{name: "When only the language has a build command", fullName: "paradisisland/rose", language: "go", languages: []string{"Go"}, want: "make"},
+		{name: "When the language is not compiled", fullName: "paradisisland/maria", language: "python", languages: []string{"Python"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.fullName}
+			if got := repo.buildCommand(tt.language, tt.languages); got != tt.want {
+				t.Errorf("Repository.buildCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_injectBuildSteps(t *testing.T) {
+	buildConfig = BuildConfig{Languages: map[string]string{"java-kotlin": "./mvnw -B package", "c-cpp": "make"}}
+	csvBuildCommands = map[string]string{"paradisisland/rose": "make all"}
+	defer func() {
+		buildConfig = BuildConfig{}
+		csvBuildCommands = map[string]string{}
+	}()
+
+	reusableWorkflow := "on: push\njobs:\n  code_analysis:\n    uses: advanced-security-demo/central-repo-test/.github/workflows/code_analysis.yml@main\n"
+	tests := []struct {
+		name      string
+		fullName  string
+		workflow  string
+		languages []string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "When the workflow has a manual build step",
+			workflow: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        include:
+        - language: java-kotlin
+          build-mode: none # autobuild fails
+        - language: python
+          build-mode: none
+    steps:
+    - name: Initialize CodeQL
+      uses: github/codeql-action/init@v3
+      with:
+        languages: ${{ matrix.language }}
+        build-mode: ${{ matrix.build-mode }}
+    - if: matrix.build-mode == 'manual'
+      shell: bash
+      run: |
+        echo 'Replace this with the commands to build your code'
+        exit 1
+
+    - name: Perform CodeQL Analysis
+      uses: github/codeql-action/analyze@v3
+`,
+			languages: []string{"Java", "Python"},
+			want: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        include:
+        - language: java-kotlin
+          build-mode: manual
+          build-command: "./mvnw -B package"
+        - language: python
+          build-mode: none
+    steps:
+    - name: Initialize CodeQL
+      uses: github/codeql-action/init@v3
+      with:
+        languages: ${{ matrix.language }}
+        build-mode: ${{ matrix.build-mode }}
+    - if: matrix.build-mode == 'manual'
+      shell: bash
+      run: ${{ matrix.build-command }}
+
+    - name: Perform CodeQL Analysis
+      uses: github/codeql-action/analyze@v3
+`,
+			wantErr: false,
+		},
+		{
+			name: "When the workflow has no manual build step",
+			workflow: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        include:
+          - build-mode: autobuild
+            language: c-cpp
+    steps:
+      - uses: github/codeql-action/init@v3
+        with:
+          languages: ${{ matrix.language }}
+      - uses: github/codeql-action/analyze@v3
+`,
+			languages: []string{"C"},
+			want: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        include:
+          - build-mode: manual
+            language: c-cpp
+            build-command: "make"
+    steps:
+      - uses: github/codeql-action/init@v3
+        with:
+          build-mode: ${{ matrix.build-mode }}
+          languages: ${{ matrix.language }}
+      - if: matrix.build-mode == 'manual'
+        name: Build
+        shell: bash
+        run: ${{ matrix.build-command }}
+      - uses: github/codeql-action/analyze@v3
+`,
+			wantErr: false,
+		},
+		{
+			name:      "When the workflow calls a reusable workflow and the repository has a build command",
+			workflow:  reusableWorkflow,
+			languages: []string{"Java"},
+			want:      "",
+			wantErr:   true,
+		},
+		{
+			name:      "When the workflow calls a reusable workflow and the repository has no build command",
+			workflow:  reusableWorkflow,
+			languages: []string{"Python"},
+			want:      reusableWorkflow,
+			wantErr:   false,
+		},
+		{
+			name:      "When the csv file has a build command for several compiled languages",
+			fullName:  "paradisisland/rose",
+			workflow:  "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:\n        include:\n        - language: go\n    steps:\n    - uses: github/codeql-action/init@v3\n",
+			languages: []string{"Go", "Java"},
+			want:      "",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "main"}
+			if len(tt.fullName) > 0 {
+				repo.FullName = tt.fullName
+			}
+			got, err := repo.injectBuildSteps([]byte(tt.workflow), tt.languages)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.injectBuildSteps() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Repository.injectBuildSteps() = %v, want %v", string(got), tt.want)
+			}
+			if err := validateWorkflow(got); err != nil {
+				t.Errorf("validateWorkflow() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// getCodeowners returns the owners of the given path from the CODEOWNERS file on the default branch.
+func (repo *Repository) getCodeowners(client Client, path string) ([]string, error) {
+	for _, location := range codeownersLocations {
+		content, _, err := repo.getFileContent(client, location, repo.DefaultBranch)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+
+		log.Printf("Using CODEOWNERS file %s for repository %s\n", location, repo.FullName)
+		return matchCodeowners(string(content), path), nil
+	}
+
+	log.Printf("No CODEOWNERS file found for repository %s\n", repo.FullName)
+	return nil, nil
+}
+
+// matchCodeowners returns the owners of the last rule that matches the path, as GitHub does.
+func matchCodeowners(codeowners string, path string) []string {
+	var owners []string
+	for _, line := range strings.Split(codeowners, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if codeownersPattern(fields[0]).MatchString(path) {
+			owners = fields[1:]
+		}
+	}
+	return owners
+}
+
+func codeownersPattern(pattern string) *regexp.Regexp {
+	// a pattern with a slash at the start or in the middle is relative to the root of the repository
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("(^|.*/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	// a pattern also matches everything in a matching directory, except "dir/*" that only matches direct children
+	if !strings.HasSuffix(pattern, "/*") {
+		expr.WriteString("(/.*)?")
+	}
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_matchCodeowners(t *testing.T) {
+	codeowners := `# default owners
+*       @paradisisland/scouts
+
+/.github/ @paradisisland/platform
+docs/*  @armin
+*.go    @eren @paradisisland/garrison
+`
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{
+			name: "When only the default rule matches",
+			path: "README.md",
+			want: []string{"@paradisisland/scouts"},
+		},
+		{
+			name: "When an anchored directory rule matches",
+			path: ".github/workflows/codeql.yml",
+			want: []string{"@paradisisland/platform"},
+		},
+		{
+			name: "When a single level rule matches",
+			path: "docs/index.md",
+			want: []string{"@armin"},
+		},
+		{
+			name: "When a single level rule does not match nested files",
+			path: "docs/api/index.md",
+			want: []string{"@paradisisland/scouts"},
+		},
+		{
+			name: "When the last matching rule wins",
+			path: "cmd/main.go",
+			want: []string{"@eren", "@paradisisland/garrison"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchCodeowners(codeowners, tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchCodeowners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ConfigFile string
+
+// codeqlConfigPath is where the CodeQL config file is committed, next to the workflow.
+const codeqlConfigPath = ".github/codeql/codeql-config.yml"
+
+// vendoredDirectories maps the languages returned by GetCodeqlLanguages to the directories their dependencies are vendored in.
+var vendoredDirectories = map[string][]string{
+	"C":          {"third_party", "third-party", "external"},
+	"Cpp":        {"third_party", "third-party", "external"},
+	"Csharp":     {"packages"},
+	"Go":         {"vendor"},
+	"JavaScript": {"node_modules", "bower_components"},
+	"Python":     {"venv", ".venv", "site-packages"},
+	"Ruby":       {"vendor"},
+	"Swift":      {"Pods", "Carthage"},
+}
+
+// detectVendoredDirectories returns the vendored directories of the detected languages as paths-ignore patterns.
+// paths-ignore applies to every language of the config file, so a directory vendored for one language is ignored for all of them.
+func (repo *Repository) detectVendoredDirectories(client Client, coverage []string) ([]string, error) {
+	names := map[string]bool{}
+	for _, language := range coverage {
+		for _, name := range vendoredDirectories[language] {
+			names[name] = true
+		}
+	}
+	if len(names) <= 0 {
+		return nil, nil
+	}
+
+	tree, err := repo.listTree(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var directories []string
+	for _, entry := range tree {
+		if entry.Type != "tree" || !names[path.Base(entry.Path)] {
+			continue
+		}
+		// directories inside an ignored directory are already covered
+		nested := false
+		for _, directory := range directories {
+			if strings.HasPrefix(entry.Path, directory+"/") {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			directories = append(directories, entry.Path)
+		}
+	}
+	sort.Strings(directories)
+
+	var pathsIgnore []string
+	for _, directory := range directories {
+		pathsIgnore = append(pathsIgnore, directory+"/**")
+	}
+	log.Printf("Vendored directories found for repository %s: %d\n", repo.FullName, len(pathsIgnore))
+	return pathsIgnore, nil
+}
+
+// renderPathsIgnore renders the patterns as a YAML flow sequence so the placeholder can be used anywhere a list is expected.
+func renderPathsIgnore(pathsIgnore []string) string {
+	if len(pathsIgnore) <= 0 {
+		return "[]"
+	}
+	var quoted []string
+	for _, pattern := range pathsIgnore {
+		content, _ := json.Marshal(pattern)
+		quoted = append(quoted, string(content))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(quoted, ", "))
+}
+
+// generateCodeqlConfigFile renders the CodeQL config template for the repository.
+func (repo *Repository) generateCodeqlConfigFile(client Client, ConfigFile string, coverage []string) ([]byte, error) {
+	content, err := os.ReadFile(ConfigFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to read CodeQL config file %s\n", ConfigFile)
+		return []byte{}, err
+	}
+
+	values := map[string]string{}
+	// the tree is only listed when the template needs it
+	if strings.Contains(string(content), "{{ .PathsIgnore }}") {
+		pathsIgnore, err := repo.detectVendoredDirectories(client, coverage)
+		if err != nil {
+			return []byte{}, err
+		}
+		values["PathsIgnore"] = renderPathsIgnore(pathsIgnore)
+	}
+	return []byte(repo.renderTemplate(string(content), values)), nil
+}
+
+// validateCodeqlConfig checks the config file is a YAML mapping with no unrendered placeholders, so a broken file fails before it is committed.
+func validateCodeqlConfig(content []byte) error {
+	problems := unrenderedPlaceholders(content)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		problems = append(problems, yamlError(err))
+	} else if len(document.Content) <= 0 || document.Content[0].Kind != yaml.MappingNode {
+		problems = append(problems, WorkflowError{Line: 1, Column: 1, Message: "the config file must be a mapping"})
+	}
+	return workflowErrors(problems)
+}
+
+// prepareCodeqlConfigFile renders and validates the CodeQL config file for the repository.
+// It returns the file with its diff against the config file on the default branch, which is empty when the file is up to date.
+func (repo *Repository) prepareCodeqlConfigFile(client Client, coverage []string) ([]byte, string, error) {
+	configFile, err := repo.generateCodeqlConfigFile(client, ConfigFile, coverage)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := validateCodeqlConfig(configFile); err != nil {
+		log.Printf("ERROR: The CodeQL config file for repository %s is invalid: %s\n", repo.FullName, err)
+		return nil, "", err
+	}
+
+	existing, sha, err := repo.getFileContent(client, codeqlConfigPath, repo.DefaultBranch)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(sha) <= 0 {
+		return configFile, unifiedDiff("/dev/null", "b/"+codeqlConfigPath, nil, configFile), nil
+	}
+	return configFile, unifiedDiff("a/"+codeqlConfigPath, "b/"+codeqlConfigPath, existing, configFile), nil
+}
+
+// commitCodeqlConfigFile commits the CodeQL config file to the workflow branch, updating the existing one if there is one.
+func (repo *Repository) commitCodeqlConfigFile(client Client, configFile []byte) error {
+	_, sha, err := repo.getFileContent(client, codeqlConfigPath, workflowBranch)
+	if err != nil {
+		return err
+	}
+
+	createdFile, err := repo.commitFile(client, workflowBranch, codeqlConfigPath, configFile, sha, "AUTOMATED: commited CodeQL config file")
+	if err != nil {
+		return err
+	}
+	log.Printf("Successfully created file %s on branch %s in repository %s\n", createdFile, workflowBranch, repo.FullName)
+	return nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepository_detectVendoredDirectories(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		coverage []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "When the repository has vendored directories for its languages",
+			fullName: "paradisisland/maria",
+			coverage: []string{"Go", "JavaScript"},
+			want:     []string{"vendor/**", "web/node_modules/**"},
+			wantErr:  false,
+		},
+		{
+			name:     "When only some of the vendored directories belong to the languages",
+			fullName: "paradisisland/maria",
+			coverage: []string{"Swift"},
+			want:     []string{"ios/Pods/**"},
+			wantErr:  false,
+		},
+		{
+			name:     "When the languages have no vendored directories",
+			fullName: "paradisisland/marley",
+			coverage: []string{"Java"},
+			want:     nil,
+			wantErr:  false,
+		},
+		{
+			name:     "When the repository is invalid",
+			fullName: "paradisisland/marley",
+			coverage: []string{"Go"},
+			want:     nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.detectVendoredDirectories(client, tt.coverage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.detectVendoredDirectories() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.detectVendoredDirectories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderPathsIgnore(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathsIgnore []string
+		want        string
+	}{
+		{
+			name:        "When there are paths to ignore",
+			pathsIgnore: []string{"vendor/**", "web/node_modules/**"},
+			want:        `["vendor/**", "web/node_modules/**"]`,
+		},
+		{
+			name:        "When there are no paths to ignore",
+			pathsIgnore: nil,
+			want:        "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderPathsIgnore(tt.pathsIgnore); got != tt.want {
+				t.Errorf("renderPathsIgnore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateCodeqlConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "When the config file is valid",
+			content: "name: CodeQL config\npaths-ignore: [\"vendor/**\"]\n",
+			wantErr: false,
+		},
+		{
+			name:    "When the config file has an unrendered placeholder",
+			content: "name: CodeQL config\npaths: {{ .Paths }}\n",
+			wantErr: true,
+		},
+		{
+			name:    "When the config file is not a mapping",
+			content: "- security-extended\n",
+			wantErr: true,
+		},
+		{
+			name:    "When the config file is not valid YAML",
+			content: "name: [CodeQL\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCodeqlConfig([]byte(tt.content)); (err != nil) != tt.wantErr {
+				t.Errorf("validateCodeqlConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepository_prepareCodeqlConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "codeql-config.yml")
+	if err := os.WriteFile(configFile, []byte("name: CodeQL config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pathsIgnoreFile := filepath.Join(dir, "paths-ignore.yml")
+	if err := os.WriteFile(pathsIgnoreFile, []byte("paths-ignore: {{ .PathsIgnore }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.yml")
+	if err := os.WriteFile(invalidFile, []byte("- security-extended\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		fullName   string
+		configFile string
+		want       string
+		wantDiff   string
+		wantErr    bool
+	}{
+		{
+			name:       "When the repository has no config file",
+			fullName:   "paradisisland/maria",
+			configFile: pathsIgnoreFile,
+			want:       "paths-ignore: [\"vendor/**\", \"web/node_modules/**\"]\n",
+			wantDiff:   "--- /dev/null\n+++ b/.github/codeql/codeql-config.yml\n@@ -0,0 +1 @@\n+paths-ignore: [\"vendor/**\", \"web/node_modules/**\"]\n",
+			wantErr:    false,
+		},
+		{
+			name:       "When the config file is up to date",
+			fullName:   "paradisisland/shiganshima",
+			configFile: configFile,
+			want:       "name: CodeQL config\n",
+			wantDiff:   "",
+			wantErr:    false,
+		},
+		{
+			name:       "When the config file is invalid",
+			fullName:   "paradisisland/maria",
+			configFile: invalidFile,
+			wantErr:    true,
+		},
+		{
+			name:       "When the config file template does not exist",
+			fullName:   "paradisisland/maria",
+			configFile: filepath.Join(dir, "missing.yml"),
+			wantErr:    true,
+		},
+		{
+			name:       "When the existing config file cannot be read",
+			fullName:   "paradisisland/marley",
+			configFile: configFile,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigFile = tt.configFile
+			defer func() { ConfigFile = "" }()
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, diff, err := repo.prepareCodeqlConfigFile(&TestClient{}, []string{"Go", "JavaScript"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.prepareCodeqlConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Repository.prepareCodeqlConfigFile() = %q, want %q", got, tt.want)
+			}
+			if diff != tt.wantDiff {
+				t.Errorf("Repository.prepareCodeqlConfigFile() diff = %q, want %q", diff, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestRepository_commitCodeqlConfigFile(t *testing.T) {
+	repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "main"}
+	if err := repo.commitCodeqlConfigFile(&TestClient{}, []byte("name: CodeQL config\n")); err != nil {
+		t.Errorf("Repository.commitCodeqlConfigFile() error = %v", err)
+	}
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/spf13/cobra"
@@ -19,6 +18,7 @@ var LogFile string
 var TemplateFile string
 var Branch string
 var CsvFile string
+var ReportFile string
 var Force bool
 var Errors = make(map[string]error)
 
@@ -29,18 +29,40 @@ func init() {
 	codeScanningCmd.PersistentFlags().StringVarP(&WorkflowFile, "workflow", "w", "", "specify the path to the code scanning workflow file")
 	codeScanningCmd.PersistentFlags().StringVarP(&TemplateFile, "template", "t", "", "specify the path to the code scanning workflow template file")
 	codeScanningCmd.MarkFlagsMutuallyExclusive("workflow", "template")
+	codeScanningCmd.PersistentFlags().StringVar(&ConfigFile, "config-file", "", "specify the path to a CodeQL config file template that is committed to .github/codeql/codeql-config.yml with the workflow")
 	codeScanningCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
 	// MarkFlagsOneRequired is only available in cobra v1.8.0 that still isn't released yet (https://github.com/spf13/cobra/issues/1936#issuecomment-1669126066)
 	// codeScanningCmd.MarkFlagsOneRequired("csv", "organization")
 	// codeScanningCmd.MarkFlagsOneRequired("workflow", "template")
+	codeScanningCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path where a JSON report of the run will be saved")
+	codeScanningCmd.PersistentFlags().StringVar(&Mode, "mode", ModeAdvanced, "specify how code scanning is enabled: advanced, default, auto or migrate")
+	codeScanningCmd.PersistentFlags().StringVar(&PolicyFile, "policy", "", "specify the path to the policy file that decides between default and advanced setup in auto mode")
+	codeScanningCmd.PersistentFlags().StringVar(&QuerySuite, "query-suite", "default", "specify the default setup query suite: default or extended")
+	codeScanningCmd.PersistentFlags().StringVar(&RunnerType, "runner-type", "standard", "specify the default setup runner type: standard or labeled")
+	codeScanningCmd.PersistentFlags().StringVar(&RunnerLabel, "runner-label", "", "specify the default setup runner label when the runner type is labeled")
+	codeScanningCmd.PersistentFlags().DurationVar(&PollInterval, "poll-interval", 10*time.Second, "specify how often the default setup configuration run is checked")
+	codeScanningCmd.PersistentFlags().DurationVar(&PollTimeout, "poll-timeout", 10*time.Minute, "specify how long to wait for the default setup configuration run")
+	codeScanningCmd.PersistentFlags().StringVar(&CentralWorkflow, "central-workflow", "", "specify the central reusable CodeQL workflow e.g. 'my-org/security/.github/workflows/codeql.yml', calls to it count as an existing CodeQL workflow")
+	codeScanningCmd.PersistentFlags().StringVar(&AnalysisMaxAge, "analysis-max-age", "30d", "skip repositories with CodeQL results uploaded within this age e.g. 30d, an empty value disables the check")
+	codeScanningCmd.PersistentFlags().BoolVar(&EnableGhas, "enable-ghas", false, "enable Advanced Security for repositories that do not have it enabled and continue the rollout")
+	codeScanningCmd.PersistentFlags().BoolVar(&SecretScanning, "secret-scanning", false, "enable secret scanning with --enable-ghas, also on repositories that already have Advanced Security")
+	codeScanningCmd.PersistentFlags().BoolVar(&PushProtection, "push-protection", false, "enable secret scanning push protection with --enable-ghas, also on repositories that already have Advanced Security")
+	codeScanningCmd.PersistentFlags().BoolVar(&DependabotSecurityUpdates, "dependabot-security-updates", false, "enable Dependabot security updates with --enable-ghas, also on repositories that already have Advanced Security")
 	codeScanningCmd.PersistentFlags().BoolVarP(&Force, "force", "f", false, "force enable code scanning advanced setup or update the existing code scanning workflow file")
+	codeScanningCmd.PersistentFlags().BoolVar(&DryRun, "dry-run", false, "log the diff of the workflow file for each repository without changing anything")
+	codeScanningCmd.PersistentFlags().BoolVar(&DiffInPullRequest, "diff-in-pr", false, "add the diff of the workflow file to the body of the pull request")
+	codeScanningCmd.MarkFlagsMutuallyExclusive("dry-run", "enable-ghas")
+	codeScanningCmd.PersistentFlags().StringVar(&BuildConfigFile, "build-config", "", "specify the path to a YAML file of manual build commands by language and by repository")
+	codeScanningCmd.PersistentFlags().BoolVar(&PinActions, "pin-actions", false, "pin the actions and reusable workflows of other owners in the workflow file to their commit sha")
+	codeScanningCmd.PersistentFlags().BoolVar(&SkipReferenceCheck, "skip-reference-check", false, "do not check that the actions and reusable workflows the workflow file uses exist and are accessible from each repository")
+	codeScanningCmd.PersistentFlags().BoolVar(&MergeWorkflow, "merge", false, "with --force, only update the triggers, action versions, reusable workflow ref and language matrix of an existing workflow file and keep the rest")
 
 }
 
 var codeScanningCmd = &cobra.Command{
 	Use:   "code-scanning",
 	Short: "Add workflow files to enable code scanning",
-	Long:  "Add / Update the codeql.yml file in a repository via a PR",
+	Long:  "Add / Update the codeql.yml file in a repository via a PR, or enable code scanning default setup",
 	Run: func(cmd *cobra.Command, args []string) {
 
 		//set up logging
@@ -48,16 +70,12 @@ var codeScanningCmd = &cobra.Command{
 			LogFile = "gh-add-files.log"
 		}
 
-		logFile, err := os.OpenFile(LogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666)
+		logFile, err := setupLogging(LogFile, os.Stdout)
 		if err != nil {
 			panic(err)
 		}
-		mw := io.MultiWriter(os.Stdout, logFile)
-		log.SetOutput(mw)
 		defer logFile.Close()
 
-		log.Printf("Logging all output to %s\n", LogFile)
-
 		// check if organization or csv file is provided
 		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
 			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
@@ -67,8 +85,41 @@ var codeScanningCmd = &cobra.Command{
 			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
 		}
 
+		// check the mode and its options
+		if Mode != ModeAdvanced && Mode != ModeDefault && Mode != ModeAuto && Mode != ModeMigrate {
+			log.Fatalf("ERROR: Unknown mode %s, must be advanced, default, auto or migrate\n", Mode)
+		} else if len(PolicyFile) > 0 && Mode != ModeAuto {
+			log.Fatalln("ERROR: The policy flag can only be used in auto mode")
+		} else if QuerySuite != "default" && QuerySuite != "extended" {
+			log.Fatalf("ERROR: Unknown query suite %s, must be default or extended\n", QuerySuite)
+		} else if RunnerType != "standard" && RunnerType != "labeled" {
+			log.Fatalf("ERROR: Unknown runner type %s, must be standard or labeled\n", RunnerType)
+		} else if RunnerType == "labeled" && len(RunnerLabel) <= 0 {
+			log.Fatalln("ERROR: The runner-label flag must be provided when the runner type is labeled")
+		}
+
+		if !EnableGhas && (SecretScanning || PushProtection || DependabotSecurityUpdates) {
+			log.Fatalln("ERROR: The secret-scanning, push-protection and dependabot-security-updates flags can only be used with the enable-ghas flag")
+		}
+
+		if MergeWorkflow && !Force {
+			log.Fatalln("ERROR: The merge flag can only be used with the force flag")
+		} else if DryRun && Mode != ModeAdvanced {
+			log.Fatalln("ERROR: The dry-run flag can only be used in advanced mode")
+		}
+
+		if len(AnalysisMaxAge) > 0 {
+			if _, err := parseAge(AnalysisMaxAge); err != nil {
+				log.Fatalln("ERROR: Invalid analysis-max-age flag: ", err)
+			}
+		}
+
 		// check if workflow or template file is provided
-		if len(WorkflowFile) <= 0 && len(TemplateFile) <= 0 {
+		if Mode == ModeDefault || Mode == ModeMigrate {
+			if len(WorkflowFile) > 0 || len(TemplateFile) > 0 || len(ConfigFile) > 0 || len(BuildConfigFile) > 0 {
+				log.Fatalf("ERROR: You cannot provide a workflow flag, template flag, config-file flag or build-config flag in %s mode\n", Mode)
+			}
+		} else if len(WorkflowFile) <= 0 && len(TemplateFile) <= 0 {
 			log.Fatalln("ERROR: Either workflow flag or template flag must be provided")
 		} else if len(WorkflowFile) > 0 && len(TemplateFile) > 0 {
 			log.Fatalln("ERROR: You cannot provide both workflow flag and template flag")
@@ -80,60 +131,40 @@ var codeScanningCmd = &cobra.Command{
 			log.Fatalln("ERROR: Unable to create REST client: ", err)
 		}
 
-		var repos []Repository
-
-		if len(CsvFile) > 0 {
-			csvFile, err := os.OpenFile(CsvFile, os.O_RDONLY, 0666)
-			if err != nil {
-				panic(err)
-			}
-
-			defer csvFile.Close()
-			csvr := csv.NewReader(csvFile)
-			var repositories []string
-
-			for {
-				row, err := csvr.Read()
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
-					log.Fatalln(err)
+		policy, err := loadPolicy(PolicyFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
 
-				}
-				repositories = append(repositories, fmt.Sprint(row[0]))
-			}
+		if err := loadBuildCommands(BuildConfigFile, CsvFile); err != nil {
+			log.Fatalln(err)
+		}
 
-			for _, repository := range repositories {
-				log.Printf("Retrieving Repository: %s \n", repository)
-				repo, err := getRepo(repository, client)
-				if err != nil {
-					Errors[repository] = err
-				}
-				repos = append(repos, repo)
-			}
-		} else if len(args) > 0 {
-			for _, repository := range args {
-				log.Printf("Retrieving Repository: %s \n", repository)
-				repo, err := getRepo(repository, client)
-				if err != nil {
-					Errors[repository] = err
-				} else {
-					repos = append(repos, repo)
-				}
-			}
-		} else {
-			log.Printf("Retrieving Repositories for the Organization: %s \n", Organization)
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
 
-			if repos, err = getRepos(Organization, client); err != nil {
+		// a missing reference breaks the PR of every repository, so the run stops before any repository is changed
+		if !SkipReferenceCheck && Mode != ModeDefault && Mode != ModeMigrate && len(repos) > 0 {
+			workflowFile, err := repos[0].renderCodeqlWorkflowFile()
+			if err != nil {
 				log.Fatalln(err)
 			}
+			if err := checkWorkflowReferencesExist(client, workflowFile); err != nil {
+				log.Fatalln("ERROR: The workflow file uses an action or reusable workflow that does not exist: ", err)
+			}
 		}
 
+		report := newRunReport()
+
 		var pullRequests []string
 		var defaultScan []string
 		var noLanguage []string
 		var advancedSetup []string
+		var defaultSetupEnabled []string
+		var upToDate []string
+		var dryRun []string
 
 		for _, repo := range repos {
 
@@ -148,6 +179,64 @@ var codeScanningCmd = &cobra.Command{
 			if len(coverage) <= 0 {
 				log.Printf("No CodeQL supported language found for repository: %s", repo.FullName)
 				noLanguage = append(noLanguage, repo.FullName)
+				report.record(repo.FullName).Status = StatusNoLanguage
+				continue
+			}
+
+			if EnableGhas {
+				enabled, impact, err := repo.ensureAdvancedSecurity(client)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+				if enabled {
+					SecurityFeaturesEnabled[repo.FullName] = impact
+				}
+			}
+
+			if Mode == ModeMigrate {
+				status, createdPR, err := repo.migrateToDefaultSetup(client)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+
+				entry := report.record(repo.FullName)
+				entry.Status = status
+				if len(createdPR) > 0 {
+					entry.Branch = migrationBranch
+					entry.PullRequest = createdPR
+					pullRequests = append(pullRequests, createdPR)
+				} else if status == StatusDefaultSetup {
+					defaultScan = append(defaultScan, repo.FullName)
+				}
+				continue
+			}
+
+			repoMode := Mode
+			if Mode == ModeAuto {
+				repoMode = policy.chooseMode(repo.FullName, coverage)
+				log.Printf("Policy selected %s setup for repository %s with languages %s\n", repoMode, repo.FullName, strings.Join(coverage, ", "))
+			}
+
+			if repoMode == ModeDefault {
+				status, conclusion, err := repo.rolloutDefaultSetup(client, coverage)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+
+				entry := report.record(repo.FullName)
+				entry.Status = status
+				entry.DefaultSetupRun = conclusion
+				switch status {
+				case StatusDefaultSetup:
+					defaultScan = append(defaultScan, repo.FullName)
+				case StatusAdvancedSetup:
+					advancedSetup = append(advancedSetup, repo.FullName)
+				default:
+					defaultSetupEnabled = append(defaultSetupEnabled, fmt.Sprintf("%s (configuration run: %s)", repo.FullName, conclusion))
+				}
 				continue
 			}
 
@@ -161,19 +250,10 @@ var codeScanningCmd = &cobra.Command{
 			if isDefaultSetupEnabled && !Force {
 				log.Printf("Default setup already enabled for this repository: %s, skipping enablement.", repo.FullName)
 				defaultScan = append(defaultScan, repo.FullName)
+				report.record(repo.FullName).Status = StatusDefaultSetup
 				continue
-			} else if isDefaultSetupEnabled && Force {
-				log.Printf("Default setup already enabled for this repository: %s, but force flag is set, converting repo to advanced setup", repo.FullName)
-
-				result, err := repo.disableDefaultSetup(client)
-				if err != nil {
-					Errors[repo.FullName] = err
-					continue
-				}
-
-				if result {
-					log.Printf("Default setup disabled for repository: %s", repo.FullName)
-				}
+			} else if isDefaultSetupEnabled && Force && DryRun {
+				log.Printf("Default setup already enabled for this repository: %s, dry run: default setup would be disabled", repo.FullName)
 			}
 
 			//check that codeql workflow file doesn't already exist
@@ -186,66 +266,134 @@ var codeScanningCmd = &cobra.Command{
 			if isCodeQLEnabled && !Force {
 				log.Printf("CodeQL workflow file already exists for this repository: %s, skipping enablement.", repo.FullName)
 				advancedSetup = append(advancedSetup, repo.FullName)
+				report.record(repo.FullName).Status = StatusAdvancedSetup
 				continue
 			} else if isCodeQLEnabled && Force {
 				log.Printf("CodeQL workflow file already exists for this repository: %s, but force flag is set, updating workflow file", repo.FullName)
 			}
 
-			newbranchref, err := repo.createBranchForRepo(client)
+			if !isCodeQLEnabled {
+				existing, workflows, err := repo.detectExistingCodeql(client)
+				if err != nil {
+					log.Println(err)
+					Errors[repo.FullName] = err
+					continue
+				}
+				if len(existing) > 0 && !Force {
+					log.Printf("%s already exists for this repository: %s, skipping enablement.", existing, repo.FullName)
+					advancedSetup = append(advancedSetup, repo.FullName)
+					report.record(repo.FullName).Status = StatusAdvancedSetup
+					continue
+				} else if len(workflows) > 0 && Force {
+					log.Printf("ERROR: %s already exists for this repository: %s, refusing to add a second CodeQL workflow file", existing, repo.FullName)
+					Errors[repo.FullName] = fmt.Errorf("%s already exists, adding codeql.yml would analyze the repository twice", existing)
+					continue
+				} else if len(existing) > 0 && Force {
+					log.Printf("%s already exists for this repository: %s, but force flag is set, adding workflow file", existing, repo.FullName)
+				}
+			}
+
+			rendered, err := repo.renderCodeqlWorkflowFile()
 			if err != nil {
-				// log.Println(err)
-				if strings.Contains(err.Error(), "already exists") && Force {
-					log.Printf("Force flag is set, removing existing branch for repository: %s\n", repo.FullName)
-					err := repo.deleteBranch(client)
-					if err != nil {
-						Errors[repo.FullName] = err
-						continue
-					}
-					log.Printf("Successfully removed branch for repository: %s\n", repo.FullName)
-					newbranchref, err = repo.createBranchForRepo(client)
-					if err != nil {
-						Errors[repo.FullName] = err
-						continue
-					}
-				} else {
+				Errors[repo.FullName] = err
+				continue
+			}
+
+			// the final file is validated before default setup is disabled, so a broken workflow leaves the repository as it was
+			workflowFile, diff, err := repo.prepareCodeqlWorkflowFile(client, rendered, coverage, isCodeQLEnabled)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			isWorkflowUpToDate := isCodeQLEnabled && len(diff) <= 0
+
+			// the config file is checked on its own, so it is committed even when the workflow is up to date
+			var configFile []byte
+			if len(ConfigFile) > 0 {
+				var configDiff string
+				configFile, configDiff, err = repo.prepareCodeqlConfigFile(client, coverage)
+				if err != nil {
 					Errors[repo.FullName] = err
 					continue
 				}
+				if len(configDiff) <= 0 {
+					configFile = nil
+				}
+				diff += configDiff
 			}
-			if len(newbranchref) <= 0 {
-				log.Println("ERROR: Unable to create new branch")
-				Errors[repo.FullName] = errors.New("Something went wrong when creating new branch")
+
+			isUpToDate := isWorkflowUpToDate && configFile == nil
+			if DryRun && !isUpToDate {
+				log.Printf("Dry run: changes to the CodeQL files for repository %s:\n%s", repo.FullName, diff)
+				dryRun = append(dryRun, repo.FullName)
+				report.record(repo.FullName).Status = StatusDryRun
+				continue
 			}
-			log.Printf("Ref created succesfully at : %s\n", newbranchref)
 
-			var workflowFile []byte
-			if len(TemplateFile) > 0 {
-				workflowFile, err = repo.generateCodeqlWorkflowFile(TemplateFile)
+			if isDefaultSetupEnabled && Force && !DryRun {
+				log.Printf("Default setup already enabled for this repository: %s, but force flag is set, converting repo to advanced setup", repo.FullName)
+
+				// keep the configuration so a rollback can restore it
+				previousConfiguration, err := repo.getDefaultSetupConfiguration(client)
 				if err != nil {
 					Errors[repo.FullName] = err
 					continue
 				}
-			} else {
-				workflowFile, err = repo.readCodeqlWorkflowFile(WorkflowFile)
+
+				result, err := repo.disableDefaultSetup(client)
 				if err != nil {
 					Errors[repo.FullName] = err
 					continue
 				}
+
+				if result {
+					log.Printf("Default setup disabled for repository: %s", repo.FullName)
+					entry := report.record(repo.FullName)
+					entry.DefaultSetupDisabled = true
+					entry.PreviousDefaultSetup = &previousConfiguration
+				}
 			}
 
-			createdFile, err := repo.commitWorkflowFile(client, workflowFile, sha)
-			if err != nil {
-				log.Println(err)
+			if isUpToDate {
+				log.Printf("CodeQL files are up to date for this repository: %s, skipping.", repo.FullName)
+				upToDate = append(upToDate, repo.FullName)
+				report.record(repo.FullName).Status = StatusUpToDate
 				continue
 			}
-			if len(createdFile) <= 0 {
-				log.Println("ERROR: Unable to create commit new file")
-				Errors[repo.FullName] = errors.New("Something went wrong when creating new file")
+
+			newbranchref, err := repo.createOrReplaceBranch(client, workflowBranch)
+			if err != nil {
+				Errors[repo.FullName] = err
 				continue
 			}
-			log.Printf("Successfully created file %s on branch %s in repository %s\n", createdFile, newbranchref, repo.FullName)
+			if len(newbranchref) <= 0 {
+				log.Println("ERROR: Unable to create new branch")
+				Errors[repo.FullName] = errors.New("Something went wrong when creating new branch")
+			}
+			log.Printf("Ref created succesfully at : %s\n", newbranchref)
+
+			if !isWorkflowUpToDate {
+				createdFile, err := repo.commitWorkflowFile(client, workflowFile, sha)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				if len(createdFile) <= 0 {
+					log.Println("ERROR: Unable to create commit new file")
+					Errors[repo.FullName] = errors.New("Something went wrong when creating new file")
+					continue
+				}
+				log.Printf("Successfully created file %s on branch %s in repository %s\n", createdFile, newbranchref, repo.FullName)
+			}
 
-			createdPR, err := repo.raisePullRequest(client)
+			if configFile != nil {
+				if err := repo.commitCodeqlConfigFile(client, configFile); err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+			}
+
+			createdPR, err := repo.raisePullRequest(client, diff, templateHash(rendered))
 			if err != nil {
 				log.Println(err)
 				continue
@@ -257,6 +405,10 @@ var codeScanningCmd = &cobra.Command{
 			}
 			log.Printf("Successfully raised pull request %s on branch %s in repository %s\n", createdPR, newbranchref, repo.FullName)
 			pullRequests = append(pullRequests, createdPR)
+			entry := report.record(repo.FullName)
+			entry.Status = StatusPullRequest
+			entry.Branch = workflowBranch
+			entry.PullRequest = createdPR
 
 		}
 		log.Printf("Number of repos processed: %d\n", len(repos))
@@ -285,6 +437,38 @@ var codeScanningCmd = &cobra.Command{
 			}
 		}
 
+		if len(defaultSetupEnabled) > 0 {
+			log.Printf("Repositories with default setup enabled: %d\n", len(defaultSetupEnabled))
+			for _, repo := range defaultSetupEnabled {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(upToDate) > 0 {
+			log.Printf("Repositories with up to date CodeQL files: %d\n", len(upToDate))
+			for _, repo := range upToDate {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(dryRun) > 0 {
+			log.Printf("Repositories that would get a pull request: %d\n", len(dryRun))
+			for _, repo := range dryRun {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(SecurityFeaturesEnabled) > 0 {
+			log.Printf("Repositories with Advanced Security enabled: %d\n", len(SecurityFeaturesEnabled))
+			for repo, impact := range SecurityFeaturesEnabled {
+				if len(impact) > 0 {
+					log.Printf("Repository: %s Committer seats: [%s]\n", repo, impact)
+				} else {
+					log.Printf("Repository: %s\n", repo)
+				}
+			}
+		}
+
 		if len(pullRequests) > 0 {
 			log.Printf("Pull requests raised: %d\n", len(pullRequests))
 			for _, pr := range pullRequests {
@@ -300,6 +484,18 @@ var codeScanningCmd = &cobra.Command{
 			}
 		}
 
+		if len(ReportFile) > 0 {
+			for repo, impact := range SecurityFeaturesEnabled {
+				entry := report.record(repo)
+				entry.AdvancedSecurityEnabled = true
+				entry.CommitterSeatImpact = impact
+			}
+			report.recordErrors(Errors)
+			if err := report.write(ReportFile); err != nil {
+				log.Println(err)
+			}
+		}
+
 		log.Printf("Finished enable code scanning! \n")
 
 	},
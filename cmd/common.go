@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,17 +14,56 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
 type Repository struct {
+	ID            int64  `json:"id"`
 	FullName      string `json:"full_name"`
 	Name          string `json:"name"`
 	DefaultBranch string `json:"default_branch"`
 }
 
+// PullRequest holds the pull request fields used to track a rollout.
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	HTMLURL        string     `json:"html_url"`
+	Body           string     `json:"body"`
+	State          string     `json:"state"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	MergedAt       *time.Time `json:"merged_at"`
+	Mergeable      *bool      `json:"mergeable"`
+	MergeableState string     `json:"mergeable_state"`
+	Head           struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"head"`
+}
+
+// DefaultSetupConfiguration is the code scanning default setup configuration of a repository.
+type DefaultSetupConfiguration struct {
+	State       string   `json:"state"`
+	Languages   []string `json:"languages,omitempty"`
+	QuerySuite  string   `json:"query_suite,omitempty"`
+	RunnerType  string   `json:"runner_type,omitempty"`
+	RunnerLabel *string  `json:"runner_label,omitempty"`
+}
+
+// committerName and committerEmail identify the commits made by add-files.
+const (
+	committerName  = "gh-cli add-files"
+	committerEmail = "security@yourcompany"
+)
+
+// workflowBranch is the branch the code scanning workflow is committed to.
+const workflowBranch = "gh-cli/codescanningworkflow"
+
 type HttpMethod int
 
 const (
@@ -61,7 +101,7 @@ func getRepos(Organization string, client Client) ([]Repository, error) {
 		log.Printf("Processing page: %d\n", page)
 		for _, repoResponse := range data {
 			//add value in data to allrepos map
-			allrepos = append(allrepos, Repository{repoResponse.FullName, repoResponse.Name, repoResponse.DefaultBranch})
+			allrepos = append(allrepos, Repository{ID: repoResponse.ID, FullName: repoResponse.FullName, Name: repoResponse.Name, DefaultBranch: repoResponse.DefaultBranch})
 		}
 
 		var hasNextPage bool
@@ -190,29 +230,34 @@ func findNextPage(nextPageLink string) (string, bool) {
 }
 
 func (repo *Repository) checkDefaultSetupEnabled(client Client) (bool, error) {
+	isDefaultSetupEnabled, _, err := repo.getDefaultSetupState(client)
+	return isDefaultSetupEnabled, err
+}
+
+func (repo *Repository) getDefaultSetupState(client Client) (bool, int, error) {
 	var defaultSetupEnabledResponse interface{}
 	requestPath := fmt.Sprintf("repos/%s/code-scanning/default-setup", repo.FullName)
 	statusCode, _, err := callApi(client, requestPath, &defaultSetupEnabledResponse, GET)
 	if statusCode == 404 {
 		log.Printf("The repository %s does not exist\n", repo.FullName)
-		return false, err
+		return false, statusCode, err
 	} else if statusCode == 403 {
 		log.Printf("ERROR: The repository %s does not have Advanced Security enabled\n", repo.FullName)
-		return false, err
+		return false, statusCode, err
 	} else if statusCode == 200 {
 
 		defaultState := gojsonq.New().FromInterface(defaultSetupEnabledResponse).Find("state")
 		if defaultState == "configured" {
 			log.Printf("WARN: The repository %s has default setup enabled\n", repo.FullName)
-			return true, nil
+			return true, statusCode, nil
 		} else {
 			log.Printf("The repository %s does not have default setup enabled\n", repo.FullName)
-			return false, nil
+			return false, statusCode, nil
 		}
 	}
 
 	log.Printf("ERROR: Unable to get default setup status for repository %s\n", repo.FullName)
-	return false, err
+	return false, statusCode, err
 
 }
 
@@ -249,7 +294,71 @@ func (repo *Repository) disableDefaultSetup(client Client) (bool, error) {
 
 }
 
+func (repo *Repository) getDefaultSetupConfiguration(client Client) (DefaultSetupConfiguration, error) {
+	var configuration DefaultSetupConfiguration
+	requestPath := fmt.Sprintf("repos/%s/code-scanning/default-setup", repo.FullName)
+	_, _, err := callApi(client, requestPath, &configuration, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get default setup configuration for repository %s\n", repo.FullName)
+		return configuration, err
+	}
+	return configuration, nil
+}
+
+// enableDefaultSetup configures default setup and returns the id of the configuration workflow run, if one was started.
+func (repo *Repository) enableDefaultSetup(client Client, configuration DefaultSetupConfiguration) (int64, error) {
+	configuration.State = "configured"
+	jsonData, err := json.Marshal(configuration)
+	if err != nil {
+		log.Printf("ERROR: Unable to marshal JSON request body\n")
+		return 0, err
+	}
+
+	var response struct {
+		RunID int64 `json:"run_id"`
+	}
+	requestPath := fmt.Sprintf("repos/%s/code-scanning/default-setup", repo.FullName)
+	statusCode, _, err := callApi(client, requestPath, &response, PATCH, jsonData)
+	if statusCode == 404 {
+		log.Printf("The repository %s does not exist\n", repo.FullName)
+		return 0, err
+	} else if statusCode == 403 {
+		log.Printf("ERROR: The repository %s does not have Advanced Security enabled\n", repo.FullName)
+		return 0, err
+	} else if statusCode == 409 {
+		log.Printf("WARN: The repository %s has another configuration run for default setup in progress\n", repo.FullName)
+		return 0, err
+	} else if statusCode == 200 || statusCode == 202 {
+		log.Printf("Successfully enabled default setup for the repository %s\n", repo.FullName)
+		return response.RunID, nil
+	}
+
+	log.Printf("ERROR: Unable to enable default setup for repository %s\n", repo.FullName)
+	return 0, err
+}
+
 func (repo *Repository) createBranchForRepo(client Client) (string, error) {
+	return repo.createNamedBranchForRepo(client, workflowBranch)
+}
+
+// createOrReplaceBranch creates the branch, and replaces it when it already exists and the force flag is set.
+func (repo *Repository) createOrReplaceBranch(client Client, branch string) (string, error) {
+	newbranchref, err := repo.createNamedBranchForRepo(client, branch)
+	if err == nil {
+		return newbranchref, nil
+	}
+	if !strings.Contains(err.Error(), "already exists") || !Force {
+		return "", err
+	}
+
+	log.Printf("Force flag is set, removing existing branch for repository: %s\n", repo.FullName)
+	if err := repo.deleteNamedBranch(client, branch); err != nil {
+		return "", err
+	}
+	return repo.createNamedBranchForRepo(client, branch)
+}
+
+func (repo *Repository) createNamedBranchForRepo(client Client, branch string) (string, error) {
 	//get sha for default
 	repoBranches := map[string]interface{}{}
 	requestPath := fmt.Sprintf("repos/%s/branches/%s", repo.FullName, repo.DefaultBranch)
@@ -269,7 +378,7 @@ func (repo *Repository) createBranchForRepo(client Client) (string, error) {
 		Sha string `json:"sha"`
 	}
 	request := RequestBody{
-		Ref: "refs/heads/gh-cli/codescanningworkflow",
+		Ref: "refs/heads/" + branch,
 		Sha: fmt.Sprint(sha),
 	}
 
@@ -333,26 +442,89 @@ func (repo *Repository) readCodeqlWorkflowFile(WorkflowFile string) ([]byte, err
 }
 
 func (repo *Repository) generateCodeqlWorkflowFile(TemplateWorkflowFile string) ([]byte, error) {
+	return repo.renderTemplateFile(TemplateWorkflowFile, nil)
+}
+
+// renderCodeqlWorkflowFile renders the template file, or reads the workflow file, for the repository.
+func (repo *Repository) renderCodeqlWorkflowFile() ([]byte, error) {
+	if len(TemplateFile) > 0 {
+		return repo.generateCodeqlWorkflowFile(TemplateFile)
+	}
+	return repo.readCodeqlWorkflowFile(WorkflowFile)
+}
+
+// prepareCodeqlWorkflowFile adds the build commands of the languages to the rendered workflow file, pins its actions, checks its references
+// and, when the repository has a codeql.yml and the merge flag is set, merges it into the existing file.
+// The result is validated and returned with its diff against the codeql.yml on the default branch.
+func (repo *Repository) prepareCodeqlWorkflowFile(client Client, workflowFile []byte, languages []string, isCodeQLEnabled bool) ([]byte, string, error) {
+	workflowFile, err := repo.injectBuildSteps(workflowFile, languages)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if PinActions {
+		workflowFile, err = repo.pinWorkflowActions(client, workflowFile)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if !SkipReferenceCheck {
+		if err := repo.verifyWorkflowReferences(client, workflowFile); err != nil {
+			return nil, "", err
+		}
+	}
+
+	diff := unifiedDiff("/dev/null", "b/.github/workflows/codeql.yml", nil, workflowFile)
+	if isCodeQLEnabled {
+		workflowFile, diff, err = repo.diffCodeqlWorkflowFile(client, workflowFile)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := repo.validateCodeqlWorkflowFile(workflowFile); err != nil {
+		return nil, "", err
+	}
+	return workflowFile, diff, nil
+}
+
+// renderTemplateFile reads the template and replaces the {{ .DefaultBranch }} placeholder and the placeholders of the values.
+func (repo *Repository) renderTemplateFile(TemplateFile string, values map[string]string) ([]byte, error) {
 	//Open file on disk
-	f, err := os.Open(TemplateWorkflowFile)
+	f, err := os.Open(TemplateFile)
 	if err != nil {
-		log.Printf("ERROR: Unable to open template workflow file %s\n", TemplateWorkflowFile)
+		log.Printf("ERROR: Unable to open template file %s\n", TemplateFile)
 		return []byte{}, err
 	}
+	defer f.Close()
 	reader := bufio.NewReader(f)
 	content, err := io.ReadAll(reader)
 	if err != nil {
-		log.Printf("ERROR: Unable to read template workflow file %s\n", TemplateWorkflowFile)
+		log.Printf("ERROR: Unable to read template file %s\n", TemplateFile)
 		return []byte{}, err
 	}
 
+	return []byte(repo.renderTemplate(string(content), values)), nil
+}
+
+// renderTemplate replaces the {{ .DefaultBranch }} placeholder and a {{ .Name }} placeholder for each of the values.
+func (repo *Repository) renderTemplate(template string, values map[string]string) string {
 	//replace repo name
-	workflowFile := strings.ReplaceAll(string(content), "{{ .DefaultBranch }}", repo.DefaultBranch)
-	return []byte(workflowFile), nil
+	rendered := strings.ReplaceAll(template, "{{ .DefaultBranch }}", repo.DefaultBranch)
+	for name, value := range values {
+		rendered = strings.ReplaceAll(rendered, fmt.Sprintf("{{ .%s }}", name), value)
+	}
+	return rendered
 }
 
 func (repo *Repository) commitWorkflowFile(client Client, WorkflowFile []byte, commitSha string) (string, error) {
-	encoded := base64.StdEncoding.EncodeToString((WorkflowFile))
+	return repo.commitFile(client, workflowBranch, ".github/workflows/codeql.yml", WorkflowFile, commitSha, "AUTOMATED: commited CodeQL file")
+}
+
+// commitFile creates or, when commitSha is set, updates the file on the branch and returns its name.
+func (repo *Repository) commitFile(client Client, branch string, path string, content []byte, commitSha string, message string) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString(content)
 
 	type Commiter struct {
 		Name  string `json:"name"`
@@ -361,19 +533,19 @@ func (repo *Repository) commitWorkflowFile(client Client, WorkflowFile []byte, c
 
 	type RequestBody struct {
 		Message   string   `json:"message"`
-		Committer Commiter `json:"commiter"`
+		Committer Commiter `json:"committer"`
 		Branch    string   `json:"branch"`
 		Content   string   `json:"content"`
 		Sha       *string  `json:"sha,omitempty"`
 	}
 
 	request := RequestBody{
-		Message: "AUTOMATED: commited CodeQL file",
+		Message: message,
 		Committer: Commiter{
-			Name:  "gh-cli add-files",
-			Email: "security@yourcompany",
+			Name:  committerName,
+			Email: committerEmail,
 		},
-		Branch:  "gh-cli/codescanningworkflow",
+		Branch:  branch,
 		Content: encoded,
 		Sha:     &commitSha,
 	}
@@ -384,22 +556,22 @@ func (repo *Repository) commitWorkflowFile(client Client, WorkflowFile []byte, c
 		return "", err
 	}
 
-	//create workflow file
+	//create file
 	var createresponse interface{}
-	requestPath := fmt.Sprintf("repos/%s/contents/.github/workflows/codeql.yml", repo.FullName)
+	requestPath := fmt.Sprintf("repos/%s/contents/%s", repo.FullName, path)
 	statusCode, _, err := callApi(client, requestPath, &createresponse, PUT, jsonData)
 	if statusCode == 404 {
-		log.Printf("ERROR: The branch \"gh-cli/codescanningworkflow\" does not exist in repo %s\n", repo.FullName)
+		log.Printf("ERROR: The branch \"%s\" does not exist in repo %s\n", branch, repo.FullName)
 		return "", err
 	} else if statusCode == 422 {
-		log.Printf("ERROR: The file \".github/workflows/codeql.yml\" already exists in repo %s\n", repo.FullName)
+		log.Printf("ERROR: The file \"%s\" already exists in repo %s\n", path, repo.FullName)
 		return "", err
 	} else if statusCode == 201 {
-		log.Printf("Successfully created CodeQL workflow file for repo %s\n", repo.FullName)
+		log.Printf("Successfully created %s for repo %s\n", path, repo.FullName)
 	} else if statusCode == 200 {
-		log.Printf("Successfully updated CodeQL workflow file for repo %s\n", repo.FullName)
+		log.Printf("Successfully updated %s for repo %s\n", path, repo.FullName)
 	} else {
-		log.Printf("ERROR: Unable to create CodeQL workflow file for repository %s\n", repo.FullName)
+		log.Printf("ERROR: Unable to create %s for repository %s\n", path, repo.FullName)
 		return "", err
 	}
 
@@ -407,14 +579,9 @@ func (repo *Repository) commitWorkflowFile(client Client, WorkflowFile []byte, c
 	return fmt.Sprint(createdFile), nil
 }
 
-func (repo *Repository) raisePullRequest(client Client) (string, error) {
-
-	type PullRequestBody struct {
-		Title string `json:"title"`
-		Head  string `json:"head"`
-		Base  string `json:"base"`
-		Body  string `json:"body"`
-	}
+// raisePullRequest opens the CodeQL workflow PR, with the diff of the workflow file in the body when the diff-in-pr flag is set.
+// raisePullRequest opens the pull request of the workflow branch, with the hash of the rendered template so refresh can tell when the template changes.
+func (repo *Repository) raisePullRequest(client Client, diff string, templateHash string) (string, error) {
 
 	pr_body := fmt.Sprintf(`
 	## What does this PR do?
@@ -438,12 +605,30 @@ func (repo *Repository) raisePullRequest(client Client) (string, error) {
 	If you require any further assistance, please contact the security team.
 	`)
 	pr_body = strings.Replace(pr_body, "\n\t", "\n", -1)
+	if DiffInPullRequest {
+		pr_body += pullRequestDiff(diff)
+	}
+	if len(templateHash) > 0 {
+		pr_body = setTemplateHash(pr_body, templateHash)
+	}
+
+	return repo.openPullRequest(client, workflowBranch, "Automated PR: CodeQL workflow added", pr_body)
+}
+
+func (repo *Repository) openPullRequest(client Client, branch string, title string, body string) (string, error) {
+
+	type PullRequestBody struct {
+		Title string `json:"title"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Body  string `json:"body"`
+	}
 
 	request := PullRequestBody{
-		Title: "Automated PR: CodeQL workflow added",
-		Head:  "gh-cli/codescanningworkflow",
+		Title: title,
+		Head:  branch,
 		Base:  repo.DefaultBranch,
-		Body:  pr_body,
+		Body:  body,
 	}
 
 	jsonData, err := json.Marshal(request)
@@ -471,17 +656,507 @@ func (repo *Repository) raisePullRequest(client Client) (string, error) {
 }
 
 func (repo *Repository) deleteBranch(client Client) error {
-	requestPath := fmt.Sprintf("repos/%s/git/refs/heads/gh-cli/codescanningworkflow", repo.FullName)
+	return repo.deleteNamedBranch(client, workflowBranch)
+}
+
+func (repo *Repository) deleteNamedBranch(client Client, branch string) error {
+	requestPath := fmt.Sprintf("repos/%s/git/refs/heads/%s", repo.FullName, branch)
 	statusCode, _, err := callApi(client, requestPath, nil, DELETE, nil)
 	if statusCode == 204 {
-		log.Printf("Successfully deleted branch for repo %s\n", repo.FullName)
+		log.Printf("Successfully deleted branch %s for repo %s\n", branch, repo.FullName)
+	} else if statusCode == 422 {
+		log.Printf("ERROR: Failed to delete branch %s for repository %s\n", branch, repo.FullName)
+		return err
+	} else {
+		log.Printf("ERROR: Unable to delete branch %s for repository %s\n", branch, repo.FullName)
+		return err
+	}
+	return nil
+
+}
+
+// RepoBranch holds the branch fields needed to decide whether a branch can be deleted.
+type RepoBranch struct {
+	Name      string `json:"name"`
+	Protected bool   `json:"protected"`
+	Commit    struct {
+		Sha string `json:"sha"`
+	} `json:"commit"`
+}
+
+// getBranch returns the branch and whether it exists.
+func (repo *Repository) getBranch(client Client, branch string) (RepoBranch, bool, error) {
+	var repoBranch RepoBranch
+	requestPath := fmt.Sprintf("repos/%s/branches/%s", repo.FullName, branch)
+	statusCode, _, err := callApi(client, requestPath, &repoBranch, GET)
+	if statusCode == 404 {
+		return repoBranch, false, nil
+	}
+	if err != nil {
+		log.Printf("ERROR: Unable to get branch %s for repository %s\n", branch, repo.FullName)
+		return repoBranch, false, err
+	}
+	return repoBranch, true, nil
+}
+
+func setupLogging(LogFile string, console io.Writer) (*os.File, error) {
+	logFile, err := os.OpenFile(LogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, err
+	}
+	mw := io.MultiWriter(console, logFile)
+	log.SetOutput(mw)
+
+	log.Printf("Logging all output to %s\n", LogFile)
+	return logFile, nil
+}
+
+func loadRepositories(client Client, Organization string, CsvFile string, args []string) ([]Repository, error) {
+	var repositories []string
+
+	if len(CsvFile) > 0 {
+		csvFile, err := os.OpenFile(CsvFile, os.O_RDONLY, 0666)
+		if err != nil {
+			log.Printf("ERROR: Unable to open csv file %s\n", CsvFile)
+			return nil, err
+		}
+		defer csvFile.Close()
+
+		csvr := csv.NewReader(csvFile)
+		// other columns, such as the build command of code-scanning, are read by the commands that use them
+		csvr.FieldsPerRecord = -1
+		for {
+			row, err := csvr.Read()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				log.Printf("ERROR: Unable to read csv file %s\n", CsvFile)
+				return nil, err
+			}
+			repositories = append(repositories, fmt.Sprint(row[0]))
+		}
+	} else if len(args) > 0 {
+		repositories = args
+	} else {
+		log.Printf("Retrieving Repositories for the Organization: %s \n", Organization)
+		return getRepos(Organization, client)
+	}
+
+	var repos []Repository
+	for _, repository := range repositories {
+		log.Printf("Retrieving Repository: %s \n", repository)
+		repo, err := getRepo(repository, client)
+		if err != nil {
+			Errors[repository] = err
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+func (repo *Repository) owner() string {
+	return strings.Split(repo.FullName, "/")[0]
+}
+
+func (repo *Repository) listPullRequests(client Client, branch string, state string) ([]PullRequest, error) {
+	requestPath := fmt.Sprintf("repos/%s/pulls?head=%s:%s&state=%s&per_page=100", repo.FullName, repo.owner(), branch, state)
+	var allPullRequests []PullRequest
+
+	for {
+		var pullRequests []PullRequest
+		statusCode, nextPage, err := callApi(client, requestPath, &pullRequests, GET)
+		if err != nil {
+			if statusCode == 404 {
+				log.Printf("ERROR: The repository %s does not exist\n", repo.FullName)
+			} else {
+				log.Printf("ERROR: Unable to list pull requests for repository %s\n", repo.FullName)
+			}
+			return allPullRequests, err
+		}
+		allPullRequests = append(allPullRequests, pullRequests...)
+
+		var hasNextPage bool
+		if requestPath, hasNextPage = findNextPage(nextPage); !hasNextPage {
+			break
+		}
+	}
+
+	return allPullRequests, nil
+}
+
+func (repo *Repository) getPullRequest(client Client, number int) (PullRequest, error) {
+	var pullRequest PullRequest
+	requestPath := fmt.Sprintf("repos/%s/pulls/%d", repo.FullName, number)
+	_, _, err := callApi(client, requestPath, &pullRequest, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get pull request #%d for repository %s\n", number, repo.FullName)
+		return pullRequest, err
+	}
+	return pullRequest, nil
+}
+
+func (repo *Repository) getReviewState(client Client, number int) (string, error) {
+	type Review struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+
+	var reviews []Review
+	requestPath := fmt.Sprintf("repos/%s/pulls/%d/reviews?per_page=100", repo.FullName, number)
+	_, _, err := callApi(client, requestPath, &reviews, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get reviews for pull request #%d in repository %s\n", number, repo.FullName)
+		return "", err
+	}
+
+	// only the latest review of each reviewer counts, comments do not change the review state
+	latest := map[string]string{}
+	for _, review := range reviews {
+		if review.State == "COMMENTED" || review.State == "PENDING" {
+			continue
+		}
+		latest[review.User.Login] = review.State
+	}
+
+	state := "NONE"
+	for _, reviewState := range latest {
+		if reviewState == "CHANGES_REQUESTED" {
+			return reviewState, nil
+		}
+		if reviewState == "APPROVED" {
+			state = reviewState
+		}
+	}
+	return state, nil
+}
+
+func (repo *Repository) getCodeqlCheckRuns(client Client, sha string) (map[string]string, error) {
+	type CheckRuns struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+
+	var checkRuns CheckRuns
+	requestPath := fmt.Sprintf("repos/%s/commits/%s/check-runs?per_page=100", repo.FullName, sha)
+	_, _, err := callApi(client, requestPath, &checkRuns, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get check runs for commit %s in repository %s\n", sha, repo.FullName)
+		return nil, err
+	}
+
+	conclusions := map[string]string{}
+	for _, checkRun := range checkRuns.CheckRuns {
+		name := strings.ToLower(checkRun.Name)
+		if !strings.Contains(name, "codeql") && !strings.Contains(name, "analy") {
+			continue
+		}
+		if checkRun.Status != "completed" {
+			conclusions[checkRun.Name] = checkRun.Status
+		} else {
+			conclusions[checkRun.Name] = checkRun.Conclusion
+		}
+	}
+	return conclusions, nil
+}
+
+func printTable(out io.Writer, headers []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func printCsv(out io.Writer, headers []string, rows [][]string) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(headers); err != nil {
+		log.Printf("ERROR: Unable to write csv output\n")
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		log.Printf("ERROR: Unable to write csv output\n")
+		return err
+	}
+	return nil
+}
+
+func printJSON(out io.Writer, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Printf("ERROR: Unable to marshal JSON output\n")
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(content))
+	return err
+}
+
+func callApiRaw(client Client, requestPath string) (int, []byte, error) {
+	response, err := client.Request(http.MethodGet, requestPath, nil)
+	if err != nil {
+		var httpError *api.HTTPError
+		errors.As(err, &httpError)
+
+		return httpError.StatusCode, nil, err
+	}
+
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		log.Println("ERROR: Unable to read response body")
+		return response.StatusCode, nil, err
+	}
+
+	return response.StatusCode, content, nil
+}
+
+// IssueComment is a comment on a pull request conversation.
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (repo *Repository) listComments(client Client, number int) ([]IssueComment, error) {
+	requestPath := fmt.Sprintf("repos/%s/issues/%d/comments?per_page=100", repo.FullName, number)
+	var allComments []IssueComment
+
+	for {
+		var comments []IssueComment
+		_, nextPage, err := callApi(client, requestPath, &comments, GET)
+		if err != nil {
+			log.Printf("ERROR: Unable to list comments for pull request #%d in repository %s\n", number, repo.FullName)
+			return allComments, err
+		}
+		allComments = append(allComments, comments...)
+
+		var hasNextPage bool
+		if requestPath, hasNextPage = findNextPage(nextPage); !hasNextPage {
+			break
+		}
+	}
+
+	return allComments, nil
+}
+
+func (repo *Repository) commentOnPullRequest(client Client, number int, body string) (string, error) {
+	type RequestBody struct {
+		Body string `json:"body"`
+	}
+
+	jsonData, err := json.Marshal(RequestBody{Body: body})
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	var createdComment interface{}
+	requestPath := fmt.Sprintf("repos/%s/issues/%d/comments", repo.FullName, number)
+	statusCode, _, err := callApi(client, requestPath, &createdComment, POST, jsonData)
+	if statusCode == 201 {
+		log.Printf("Successfully commented on pull request #%d in repository %s\n", number, repo.FullName)
+	} else {
+		log.Printf("ERROR: Unable to comment on pull request #%d in repository %s\n", number, repo.FullName)
+		return "", err
+	}
+
+	commentURL := gojsonq.New().FromInterface(createdComment).Find("html_url")
+	return fmt.Sprint(commentURL), nil
+}
+
+// getFileContent returns the decoded content and blob sha of a file at the given ref.
+// A file that does not exist is not an error, it returns empty content and sha.
+func (repo *Repository) getFileContent(client Client, path string, ref string) ([]byte, string, error) {
+	type FileContent struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		Sha      string `json:"sha"`
+	}
+
+	var file FileContent
+	requestPath := fmt.Sprintf("repos/%s/contents/%s?ref=%s", repo.FullName, path, ref)
+	statusCode, _, err := callApi(client, requestPath, &file, GET)
+	if statusCode == 404 {
+		return nil, "", nil
+	}
+	if err != nil {
+		log.Printf("ERROR: Unable to get file %s in repository %s\n", path, repo.FullName)
+		return nil, "", err
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		log.Printf("ERROR: Unable to decode file %s in repository %s\n", path, repo.FullName)
+		return nil, "", err
+	}
+	return content, file.Sha, nil
+}
+
+func (repo *Repository) closePullRequest(client Client, number int) error {
+	type RequestBody struct {
+		State string `json:"state"`
+	}
+
+	jsonData, err := json.Marshal(RequestBody{State: "closed"})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	requestPath := fmt.Sprintf("repos/%s/pulls/%d", repo.FullName, number)
+	statusCode, _, err := callApi(client, requestPath, nil, PATCH, jsonData)
+	if statusCode == 200 {
+		log.Printf("Successfully closed pull request #%d in repository %s\n", number, repo.FullName)
+	} else {
+		log.Printf("ERROR: Unable to close pull request #%d in repository %s\n", number, repo.FullName)
+		return err
+	}
+	return nil
+}
+
+func (repo *Repository) updatePullRequestBody(client Client, number int, body string) error {
+	type RequestBody struct {
+		Body string `json:"body"`
+	}
+
+	jsonData, err := json.Marshal(RequestBody{Body: body})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	requestPath := fmt.Sprintf("repos/%s/pulls/%d", repo.FullName, number)
+	statusCode, _, err := callApi(client, requestPath, nil, PATCH, jsonData)
+	if statusCode != 200 {
+		log.Printf("ERROR: Unable to update the body of pull request #%d in repository %s\n", number, repo.FullName)
+		return err
+	}
+	return nil
+}
+
+// updatePullRequestBranch merges the base branch into the pull request branch.
+// It returns false when the branch is already up to date.
+func (repo *Repository) updatePullRequestBranch(client Client, number int, expectedHeadSha string) (bool, error) {
+	type RequestBody struct {
+		ExpectedHeadSha string `json:"expected_head_sha,omitempty"`
+	}
+
+	jsonData, err := json.Marshal(RequestBody{ExpectedHeadSha: expectedHeadSha})
+	if err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	var response interface{}
+	requestPath := fmt.Sprintf("repos/%s/pulls/%d/update-branch", repo.FullName, number)
+	statusCode, _, err := callApi(client, requestPath, &response, PUT, jsonData)
+	if statusCode == 202 {
+		log.Printf("Successfully requested update of pull request #%d branch in repository %s\n", number, repo.FullName)
+		return true, nil
+	} else if statusCode == 422 && err != nil && strings.Contains(err.Error(), "no new commits") {
+		log.Printf("The branch of pull request #%d in repository %s is already up to date\n", number, repo.FullName)
+		return false, nil
 	} else if statusCode == 422 {
-		log.Printf("ERROR: Failed to delete branch for repository %s\n", repo.FullName)
+		log.Printf("ERROR: The branch of pull request #%d in repository %s could not be updated, the head has changed or there is a merge conflict\n", number, repo.FullName)
+		return false, err
+	}
+
+	log.Printf("ERROR: Unable to update the branch of pull request #%d in repository %s\n", number, repo.FullName)
+	return false, err
+}
+
+func (repo *Repository) getCommitDate(client Client, sha string) (time.Time, error) {
+	type Commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+
+	var commit Commit
+	requestPath := fmt.Sprintf("repos/%s/commits/%s", repo.FullName, sha)
+	_, _, err := callApi(client, requestPath, &commit, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get commit %s for repository %s\n", sha, repo.FullName)
+		return time.Time{}, err
+	}
+	return commit.Commit.Committer.Date, nil
+}
+
+func (repo *Repository) deleteFile(client Client, path string, branch string, sha string, message string) error {
+	type Commiter struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	type RequestBody struct {
+		Message   string   `json:"message"`
+		Committer Commiter `json:"committer"`
+		Branch    string   `json:"branch"`
+		Sha       string   `json:"sha"`
+	}
+
+	request := RequestBody{
+		Message: message,
+		Committer: Commiter{
+			Name:  committerName,
+			Email: committerEmail,
+		},
+		Branch: branch,
+		Sha:    sha,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	var response interface{}
+	requestPath := fmt.Sprintf("repos/%s/contents/%s", repo.FullName, path)
+	statusCode, _, err := callApi(client, requestPath, &response, DELETE, jsonData)
+	if statusCode == 200 {
+		log.Printf("Successfully deleted file %s on branch %s in repo %s\n", path, branch, repo.FullName)
+	} else if statusCode == 404 {
+		log.Printf("ERROR: The file \"%s\" does not exist on branch %s in repo %s\n", path, branch, repo.FullName)
 		return err
 	} else {
-		log.Printf("ERROR: Unable to delete branch for repository %s\n", repo.FullName)
+		log.Printf("ERROR: Unable to delete file %s in repository %s\n", path, repo.FullName)
 		return err
 	}
 	return nil
+}
+
+// TreeEntry is a file or directory in the git tree of a repository.
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// listTree returns every file and directory on the default branch.
+func (repo *Repository) listTree(client Client) ([]TreeEntry, error) {
+	type Tree struct {
+		Tree      []TreeEntry `json:"tree"`
+		Truncated bool        `json:"truncated"`
+	}
 
+	var tree Tree
+	requestPath := fmt.Sprintf("repos/%s/git/trees/%s?recursive=1", repo.FullName, repo.DefaultBranch)
+	_, _, err := callApi(client, requestPath, &tree, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get the file tree for repository %s\n", repo.FullName)
+		return nil, err
+	}
+	if tree.Truncated {
+		log.Printf("WARN: The file tree of repository %s is too large to list completely, some files will be missed\n", repo.FullName)
+	}
+	return tree.Tree, nil
 }
@@ -1081,7 +1081,7 @@ func TestRepository_raisePullRequest(t *testing.T) {
 				Name:          tt.fields.Name,
 				DefaultBranch: tt.fields.DefaultBranch,
 			}
-			got, err := repo.raisePullRequest(client)
+			got, err := repo.raisePullRequest(client, "", "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Repository.raisePullRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1169,3 +1169,418 @@ func TestRepository_deleteBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_listPullRequests(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "When the repository has a rollout pull request",
+			fields:  fields{FullName: "paradisisland/maria"},
+			want:    []int{1347},
+			wantErr: false,
+		},
+		{
+			name:    "When the repository has no rollout pull request",
+			fields:  fields{FullName: "paradisisland/rose"},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.listPullRequests(client, workflowBranch, "all")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.listPullRequests() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var numbers []int
+			for _, pullRequest := range got {
+				numbers = append(numbers, pullRequest.Number)
+			}
+			if !reflect.DeepEqual(numbers, tt.want) {
+				t.Errorf("Repository.listPullRequests() = %v, want %v", numbers, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getReviewState(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		number  int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "When the latest review of every reviewer is an approval",
+			fields:  fields{FullName: "paradisisland/maria"},
+			number:  1347,
+			want:    "APPROVED",
+			wantErr: false,
+		},
+		{
+			name:    "When a reviewer requested changes",
+			fields:  fields{FullName: "paradisisland/rose"},
+			number:  12,
+			want:    "CHANGES_REQUESTED",
+			wantErr: false,
+		},
+		{
+			name:    "When the pull request has no reviews",
+			fields:  fields{FullName: "paradisisland/shiganshima"},
+			number:  3,
+			want:    "NONE",
+			wantErr: false,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			number:  1,
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.getReviewState(client, tt.number)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getReviewState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.getReviewState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getCodeqlCheckRuns(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "When the commit has CodeQL check runs",
+			fields: fields{FullName: "paradisisland/maria"},
+			want: map[string]string{
+				"code_analysis / Analyze (go)":   "success",
+				"code_analysis / Analyze (java)": "failure",
+				"CodeQL":                         "in_progress",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.getCodeqlCheckRuns(client, "aa218f56b14c9653891f9e74264a383fa43fefbd")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getCodeqlCheckRuns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.getCodeqlCheckRuns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_commentOnPullRequest(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		number  int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "When the pull request exists",
+			fields:  fields{FullName: "paradisisland/maria"},
+			number:  1347,
+			want:    "https://github.com/paradisisland/maria/pull/1347#issuecomment-1",
+			wantErr: false,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			number:  1,
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.commentOnPullRequest(client, tt.number, "Reminder")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.commentOnPullRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.commentOnPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getFileContent(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []byte
+		want1   string
+		wantErr bool
+	}{
+		{
+			name:    "When the file exists on the branch",
+			fields:  fields{FullName: "paradisisland/shiganshima"},
+			want:    []byte("name: CodeQL\n"),
+			want1:   "0ae040b692ec3e927163db2b984135aa3c088cba",
+			wantErr: false,
+		},
+		{
+			name:    "When the file does not exist on the branch",
+			fields:  fields{FullName: "paradisisland/maria"},
+			want:    nil,
+			want1:   "",
+			wantErr: false,
+		},
+		{
+			name:    "When the request fails",
+			fields:  fields{FullName: "paradisisland/marley"},
+			want:    nil,
+			want1:   "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, got1, err := repo.getFileContent(client, ".github/workflows/codeql.yml", workflowBranch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getFileContent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.getFileContent() got = %q, want %q", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("Repository.getFileContent() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestRepository_updatePullRequestBranch(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		number  int
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "When the branch is behind the base branch",
+			fields:  fields{FullName: "paradisisland/maria"},
+			number:  1347,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "When the branch is already up to date",
+			fields:  fields{FullName: "paradisisland/rose"},
+			number:  12,
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:    "When the head of the branch has changed",
+			fields:  fields{FullName: "paradisisland/shiganshima"},
+			number:  3,
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			number:  1,
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.updatePullRequestBranch(client, tt.number, "aa218f56b14c9653891f9e74264a383fa43fefbd")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.updatePullRequestBranch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.updatePullRequestBranch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getDefaultSetupConfiguration(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    DefaultSetupConfiguration
+		wantErr bool
+	}{
+		{
+			name:   "When default setup is configured",
+			fields: fields{FullName: "paradisisland/sheena"},
+			want: DefaultSetupConfiguration{
+				State:      "configured",
+				Languages:  []string{"ruby", "python"},
+				QuerySuite: "default",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "When the repository does not have Advanced Security enabled",
+			fields:  fields{FullName: "paradisisland/rose"},
+			want:    DefaultSetupConfiguration{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			got, err := repo.getDefaultSetupConfiguration(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getDefaultSetupConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.getDefaultSetupConfiguration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_enableDefaultSetup(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr bool
+	}{
+		{
+			name:    "When default setup can be enabled",
+			fields:  fields{FullName: "paradisisland/maria"},
+			wantErr: false,
+		},
+		{
+			name:    "When the repository does not have Advanced Security enabled",
+			fields:  fields{FullName: "paradisisland/rose"},
+			wantErr: true,
+		},
+		{
+			name:    "When the repository is invalid",
+			fields:  fields{FullName: "paradisisland/marley"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			configuration := DefaultSetupConfiguration{Languages: []string{"go"}, QuerySuite: "extended"}
+			if _, err := repo.enableDefaultSetup(client, configuration); (err != nil) != tt.wantErr {
+				t.Errorf("Repository.enableDefaultSetup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepository_deleteFile(t *testing.T) {
+	type fields struct {
+		FullName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr bool
+	}{
+		{
+			name:    "When the file exists on the branch",
+			fields:  fields{FullName: "paradisisland/shiganshima"},
+			wantErr: false,
+		},
+		{
+			name:    "When the file does not exist on the branch",
+			fields:  fields{FullName: "paradisisland/marley"},
+			wantErr: true,
+		},
+		{
+			name:    "When the sha does not match the file",
+			fields:  fields{FullName: "paradisisland/rose"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName}
+			err := repo.deleteFile(client, ".github/workflows/codeql.yml", migrationBranch, "0ae040b692ec3e927163db2b984135aa3c088cba", "AUTOMATED: removed CodeQL workflow file")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.deleteFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
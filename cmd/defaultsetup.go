@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	ModeAdvanced = "advanced"
+	ModeDefault  = "default"
+)
+
+// StatusDefaultSetupEnabled is the report status of a repository where default setup was enabled.
+const StatusDefaultSetupEnabled = "default-setup-enabled"
+
+var Mode string
+var QuerySuite string
+var RunnerType string
+var RunnerLabel string
+var PollInterval time.Duration
+var PollTimeout time.Duration
+
+// defaultSetupLanguageNames maps the languages returned by GetCodeqlLanguages to the default setup language names.
+var defaultSetupLanguageNames = map[string]string{
+	"C":          "c-cpp",
+	"Cpp":        "c-cpp",
+	"Csharp":     "csharp",
+	"Go":         "go",
+	"Java":       "java-kotlin",
+	"Kotlin":     "java-kotlin",
+	"JavaScript": "javascript-typescript",
+	"Python":     "python",
+	"Ruby":       "ruby",
+	"Swift":      "swift",
+}
+
+func defaultSetupLanguages(coverage []string) []string {
+	var languages []string
+	seen := map[string]bool{}
+	for _, language := range coverage {
+		name, ok := defaultSetupLanguageNames[language]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		languages = append(languages, name)
+	}
+	return languages
+}
+
+// rolloutDefaultSetup enables default setup for the repository and waits for the configuration run to finish.
+// It returns the report status of the repository and the conclusion of the configuration run.
+func (repo *Repository) rolloutDefaultSetup(client Client, coverage []string) (string, string, error) {
+	isDefaultSetupEnabled, err := repo.checkDefaultSetupEnabled(client)
+	if err != nil {
+		return "", "", err
+	}
+	if isDefaultSetupEnabled && !Force {
+		log.Printf("Default setup already enabled for this repository: %s, skipping enablement.", repo.FullName)
+		return StatusDefaultSetup, "", nil
+	}
+
+	isCodeQLEnabled, _, err := repo.doesCodeqlWorkflowExist(client)
+	if err != nil {
+		return "", "", err
+	}
+	if isCodeQLEnabled && !Force {
+		log.Printf("CodeQL workflow file already exists for this repository: %s, skipping default setup.", repo.FullName)
+		return StatusAdvancedSetup, "", nil
+	}
+
+	if !isCodeQLEnabled {
+		existing, _, err := repo.detectExistingCodeql(client)
+		if err != nil {
+			return "", "", err
+		}
+		if len(existing) > 0 && !Force {
+			log.Printf("%s already exists for this repository: %s, skipping default setup.", existing, repo.FullName)
+			return StatusAdvancedSetup, "", nil
+		}
+	}
+
+	configuration := DefaultSetupConfiguration{
+		Languages:  defaultSetupLanguages(coverage),
+		QuerySuite: QuerySuite,
+		RunnerType: RunnerType,
+	}
+	if RunnerType == "labeled" {
+		configuration.RunnerLabel = &RunnerLabel
+	}
+
+	runID, err := repo.enableDefaultSetup(client, configuration)
+	if err != nil {
+		return "", "", err
+	}
+	if runID == 0 {
+		return StatusDefaultSetupEnabled, "", nil
+	}
+
+	conclusion, err := repo.waitForWorkflowRun(client, runID)
+	if err != nil {
+		return "", "", err
+	}
+	return StatusDefaultSetupEnabled, conclusion, nil
+}
+
+// waitForWorkflowRun polls the workflow run until it has completed and returns its conclusion.
+func (repo *Repository) waitForWorkflowRun(client Client, runID int64) (string, error) {
+	type WorkflowRun struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	}
+
+	deadline := time.Now().Add(PollTimeout)
+	requestPath := fmt.Sprintf("repos/%s/actions/runs/%d", repo.FullName, runID)
+	for {
+		var run WorkflowRun
+		_, _, err := callApi(client, requestPath, &run, GET)
+		if err != nil {
+			log.Printf("ERROR: Unable to get workflow run %d for repository %s\n", runID, repo.FullName)
+			return "", err
+		}
+
+		if run.Status == "completed" {
+			log.Printf("Configuration run %d for repository %s completed: %s\n", runID, repo.FullName, run.Conclusion)
+			return run.Conclusion, nil
+		}
+
+		if time.Now().After(deadline) {
+			log.Printf("WARN: Configuration run %d for repository %s did not complete in %s\n", runID, repo.FullName, PollTimeout)
+			return run.Status, nil
+		}
+
+		log.Printf("Configuration run %d for repository %s is %s, waiting\n", runID, repo.FullName, run.Status)
+		time.Sleep(PollInterval)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_defaultSetupLanguages(t *testing.T) {
+	tests := []struct {
+		name     string
+		coverage []string
+		want     []string
+	}{
+		{
+			name:     "When the languages map to default setup languages",
+			coverage: []string{"Go", "Java", "JavaScript", "Python"},
+			want:     []string{"go", "java-kotlin", "javascript-typescript", "python"},
+		},
+		{
+			name:     "When several languages map to the same default setup language",
+			coverage: []string{"C", "Cpp", "Java", "Kotlin"},
+			want:     []string{"c-cpp", "java-kotlin"},
+		},
+		{
+			name:     "When there are no languages",
+			coverage: []string{},
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultSetupLanguages(tt.coverage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("defaultSetupLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_waitForWorkflowRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "When the configuration run has completed",
+			fullName: "paradisisland/maria",
+			want:     "success",
+			wantErr:  false,
+		},
+		{
+			name:     "When the repository is invalid",
+			fullName: "paradisisland/marley",
+			want:     "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.waitForWorkflowRun(client, 42)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.waitForWorkflowRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.waitForWorkflowRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
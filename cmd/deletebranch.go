@@ -2,62 +2,360 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/spf13/cobra"
 )
 
+var DryRun bool
+var SafeMode bool
+var TrustTokenUser bool
+var BranchPattern string
+var OlderThan string
+
+const (
+	BranchDeleted       = "deleted"
+	BranchWouldDelete   = "would-delete"
+	BranchNotFound      = "not-found"
+	BranchProtected     = "protected"
+	BranchDefaultBranch = "default-branch"
+	BranchRecent        = "recent"
+	BranchForeignCommit = "foreign-commits"
+	BranchOpenPR        = "open-pull-request"
+	BranchFailed        = "failed"
+)
+
 func init() {
-	deleteBranchCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to implement code scanning")
-	deleteBranchCmd.MarkPersistentFlagRequired("organization")
+	deleteBranchCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to delete the branch from")
+	deleteBranchCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	deleteBranchCmd.MarkFlagsMutuallyExclusive("csv", "organization")
 	deleteBranchCmd.PersistentFlags().StringVarP(&LogFile, "log-file", "l", "", "specify the path where the log file will be saved")
 	deleteBranchCmd.MarkPersistentFlagRequired("log-file")
 	deleteBranchCmd.PersistentFlags().StringVarP(&Branch, "branch", "b", "", "specify the branch to delete")
-	deleteBranchCmd.MarkPersistentFlagRequired("branch")
+	deleteBranchCmd.PersistentFlags().StringVarP(&BranchPattern, "pattern", "p", "", "specify a glob pattern of the branches to delete e.g. 'gh-cli/*'")
+	deleteBranchCmd.MarkFlagsMutuallyExclusive("branch", "pattern")
+	deleteBranchCmd.PersistentFlags().StringVar(&OlderThan, "older-than", "", "only delete branches whose last commit is older than this age e.g. 30d, 2w or 12h")
+	deleteBranchCmd.PersistentFlags().BoolVar(&DryRun, "dry-run", false, "list the branches that would be deleted without deleting them")
+	deleteBranchCmd.PersistentFlags().BoolVar(&SafeMode, "safe", false, "only delete branches whose commits were all made by add-files and that have no open pull request")
+	deleteBranchCmd.PersistentFlags().BoolVar(&TrustTokenUser, "trust-token-user", false, "with --safe, also count the commits of the token user as made by add-files, for branches of older versions that committed as the token user")
 }
 
 var deleteBranchCmd = &cobra.Command{
 	Use:   "delete-branch",
 	Short: "Deletes branch",
-	Long:  "Deletes named branch on each repo in organisation",
+	Long:  "Deletes named branch on each repo in organisation, csv file or argument list",
 	Run: func(cmd *cobra.Command, args []string) {
 		//set up logging
-		logFile, err := os.OpenFile(LogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666)
+		logFile, err := setupLogging(LogFile, os.Stdout)
 		if err != nil {
 			panic(err)
 		}
-		mw := io.MultiWriter(os.Stdout, logFile)
-		log.SetOutput(mw)
 		defer logFile.Close()
+
+		// check if organization or csv file is provided
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		if len(Branch) <= 0 && len(BranchPattern) <= 0 {
+			log.Fatalln("ERROR: Either branch flag or pattern flag must be provided")
+		}
+
+		var maxAge time.Duration
+		if len(OlderThan) > 0 {
+			if maxAge, err = parseAge(OlderThan); err != nil {
+				log.Fatalln("ERROR: Invalid older-than flag: ", err)
+			}
+		}
+
 		log.Println("Set up REST API Client for GitHub interactions")
 		client, err := api.DefaultRESTClient()
 		if err != nil {
 			log.Fatalln(err)
 		}
 
-		log.Printf("Retrieving Repositories for the Organization: %s .\n", Organization)
-		var repos []Repository
-
-		if repos, err = getRepos(Organization, client); err != nil {
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
 			log.Fatalln(err)
 		}
 
+		outcomes := map[string][]string{}
 		for _, repo := range repos {
-
 			log.Printf("Details for Repository: Full Name: %s; Name: %s; Default Branch: %s\n", repo.FullName, repo.Name, repo.DefaultBranch)
-			var resp interface{}
-			err := client.Delete(fmt.Sprintf("repos/%s/git/refs/heads/%s", repo.FullName, Branch), &resp)
-			if err != nil {
-				log.Println(err)
+
+			branches := []string{Branch}
+			if len(BranchPattern) > 0 {
+				branches, err = repo.listMatchingBranches(client, BranchPattern)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+				log.Printf("Branches matching %s in repository %s: %d\n", BranchPattern, repo.FullName, len(branches))
+			}
+
+			for _, branch := range branches {
+				var outcome string
+				if maxAge > 0 {
+					outcome = repo.checkBranchAge(client, branch, maxAge)
+				}
+				if len(outcome) <= 0 {
+					outcome = repo.deleteBranchSafely(client, branch)
+				}
+				outcomes[outcome] = append(outcomes[outcome], fmt.Sprintf("%s Branch: %s", repo.FullName, branch))
 			}
-			if resp != nil {
-				log.Println(resp)
+		}
+
+		log.Printf("Number of repos processed: %d\n", len(repos))
+		for _, outcome := range []string{BranchDeleted, BranchWouldDelete, BranchNotFound, BranchProtected, BranchDefaultBranch, BranchRecent, BranchForeignCommit, BranchOpenPR, BranchFailed} {
+			if len(outcomes[outcome]) <= 0 {
+				continue
+			}
+			log.Printf("Branches %s: %d\n", outcome, len(outcomes[outcome]))
+			for _, branch := range outcomes[outcome] {
+				log.Printf("Repository: %s\n", branch)
 			}
+		}
 
-			log.Printf("Successfully deleted branch %s from repository %s\n", Branch, repo.FullName)
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
 		}
 	},
 }
+
+// deleteBranchSafely deletes the branch unless it is the default branch or protected, and returns the outcome.
+func (repo *Repository) deleteBranchSafely(client Client, branch string) string {
+	if branch == repo.DefaultBranch {
+		log.Printf("WARN: Branch %s is the default branch of repository %s, skipping\n", branch, repo.FullName)
+		return BranchDefaultBranch
+	}
+
+	repoBranch, exists, err := repo.getBranch(client, branch)
+	if err != nil {
+		Errors[repo.FullName] = err
+		return BranchFailed
+	}
+	if !exists {
+		log.Printf("Branch %s does not exist in repository %s\n", branch, repo.FullName)
+		return BranchNotFound
+	}
+	if repoBranch.Protected {
+		log.Printf("WARN: Branch %s is protected in repository %s, skipping\n", branch, repo.FullName)
+		return BranchProtected
+	}
+
+	if SafeMode {
+		outcome, err := repo.checkBranchOwnership(client, branch)
+		if err != nil {
+			Errors[repo.FullName] = err
+			return BranchFailed
+		}
+		if len(outcome) > 0 {
+			return outcome
+		}
+	}
+
+	if DryRun {
+		log.Printf("Branch %s would be deleted from repository %s\n", branch, repo.FullName)
+		return BranchWouldDelete
+	}
+
+	if err := repo.deleteNamedBranch(client, branch); err != nil {
+		Errors[repo.FullName] = err
+		return BranchFailed
+	}
+	return BranchDeleted
+}
+
+// checkBranchOwnership returns the reason the branch must be kept, or an empty string when add-files owns it.
+func (repo *Repository) checkBranchOwnership(client Client, branch string) (string, error) {
+	pullRequests, err := repo.listPullRequests(client, branch, "open")
+	if err != nil {
+		return "", err
+	}
+	if len(pullRequests) > 0 {
+		log.Printf("WARN: Branch %s in repository %s has an open pull request %s, skipping\n", branch, repo.FullName, pullRequests[0].HTMLURL)
+		return BranchOpenPR, nil
+	}
+
+	comparison, err := repo.compareBranch(client, branch)
+	if err != nil {
+		return "", err
+	}
+
+	var login string
+	if TrustTokenUser {
+		login = getAuthenticatedLogin(client)
+	}
+	for _, commit := range comparison.Commits {
+		if !repo.isOwnedCommit(commit, login) {
+			author := commit.Commit.Author
+			log.Printf("WARN: Branch %s in repository %s has commit %s by %s <%s>, skipping\n", branch, repo.FullName, commit.Sha, author.Name, author.Email)
+			return BranchForeignCommit, nil
+		}
+	}
+	return "", nil
+}
+
+// compareBranch returns the commits of the branch that are not on the default branch.
+func (repo *Repository) compareBranch(client Client, branch string) (Comparison, error) {
+	var comparison Comparison
+	requestPath := fmt.Sprintf("repos/%s/compare/%s...%s", repo.FullName, repo.DefaultBranch, branch)
+	if _, _, err := callApi(client, requestPath, &comparison, GET); err != nil {
+		log.Printf("ERROR: Unable to compare branch %s with %s in repository %s\n", branch, repo.DefaultBranch, repo.FullName)
+		return comparison, err
+	}
+	return comparison, nil
+}
+
+// Identity is the name and email of a commit author.
+type Identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ComparedCommit is a commit of a branch that is not on the default branch.
+type ComparedCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Author  Identity `json:"author"`
+		Message string   `json:"message"`
+	} `json:"commit"`
+	// Author is the GitHub account of the commit author, if there is one
+	Author  *Account       `json:"author"`
+	Parents []CommitParent `json:"parents"`
+}
+
+// Account is a GitHub user or app account.
+type Account struct {
+	Login string `json:"login"`
+}
+
+// CommitParent is a parent of a commit.
+type CommitParent struct {
+	Sha string `json:"sha"`
+}
+
+// Comparison holds the commits of a branch that are not on the default branch.
+type Comparison struct {
+	Commits []ComparedCommit `json:"commits"`
+}
+
+// isOwnedCommit reports whether add-files made the commit, which is when it has the add-files committer identity.
+// With the trust-token-user flag the commits of the token user, which authored the workflow commits of older versions and the merges of update-branch, are owned too.
+func (repo *Repository) isOwnedCommit(commit ComparedCommit, login string) bool {
+	author := commit.Commit.Author
+	if author.Name == committerName && author.Email == committerEmail {
+		return true
+	}
+	return TrustTokenUser && len(login) > 0 && commit.Author != nil && strings.EqualFold(commit.Author.Login, login)
+}
+
+// authenticatedLogin caches the login of the token user for the run.
+var authenticatedLogin *string
+
+// getAuthenticatedLogin returns the login of the token user, or an empty string when the token cannot read it e.g. a GitHub App token.
+func getAuthenticatedLogin(client Client) string {
+	if authenticatedLogin != nil {
+		return *authenticatedLogin
+	}
+
+	var user Account
+	if _, _, err := callApi(client, "user", &user, GET); err != nil {
+		log.Println("WARN: Unable to get the authenticated user, only commits of the add-files committer identity are owned by add-files")
+	}
+	authenticatedLogin = &user.Login
+	return user.Login
+}
+
+// listMatchingBranches returns the branches of the repository that match the glob pattern.
+func (repo *Repository) listMatchingBranches(client Client, pattern string) ([]string, error) {
+	type Ref struct {
+		Ref string `json:"ref"`
+	}
+
+	// the matching-refs API only filters by prefix, so the rest of the pattern is matched here
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	var refs []Ref
+	requestPath := fmt.Sprintf("repos/%s/git/matching-refs/heads/%s", repo.FullName, prefix)
+	_, _, err := callApi(client, requestPath, &refs, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to list branches matching %s in repository %s\n", pattern, repo.FullName)
+		return nil, err
+	}
+
+	var branches []string
+	for _, ref := range refs {
+		branch := strings.TrimPrefix(ref.Ref, "refs/heads/")
+		matched, err := path.Match(pattern, branch)
+		if err != nil {
+			log.Printf("ERROR: Invalid branch pattern %s\n", pattern)
+			return nil, err
+		}
+		if matched {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// checkBranchAge returns BranchRecent when the last commit of the branch is newer than maxAge.
+func (repo *Repository) checkBranchAge(client Client, branch string, maxAge time.Duration) string {
+	repoBranch, exists, err := repo.getBranch(client, branch)
+	if err != nil {
+		Errors[repo.FullName] = err
+		return BranchFailed
+	}
+	if !exists {
+		return BranchNotFound
+	}
+
+	commitDate, err := repo.getCommitDate(client, repoBranch.Commit.Sha)
+	if err != nil {
+		Errors[repo.FullName] = err
+		return BranchFailed
+	}
+	if time.Since(commitDate) < maxAge {
+		log.Printf("Branch %s in repository %s was last changed on %s, skipping\n", branch, repo.FullName, commitDate.Format(time.RFC3339))
+		return BranchRecent
+	}
+	return ""
+}
+
+// parseAge parses an age such as 30d, 2w or 12h.
+func parseAge(age string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	if len(age) < 2 {
+		return 0, fmt.Errorf("age %s must be a number followed by h, d or w", age)
+	}
+	unit, ok := units[age[len(age)-1:]]
+	if !ok {
+		return 0, fmt.Errorf("age %s must be a number followed by h, d or w", age)
+	}
+	count, err := strconv.Atoi(age[:len(age)-1])
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("age %s must be a positive number followed by h, d or w", age)
+	}
+	return time.Duration(count) * unit, nil
+}
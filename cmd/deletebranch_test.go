@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRepository_deleteBranchSafely(t *testing.T) {
+	type fields struct {
+		FullName      string
+		DefaultBranch string
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		branch         string
+		dryRun         bool
+		safeMode       bool
+		trustTokenUser bool
+		want           string
+	}{
+		{
+			name:   "When the branch exists and is not protected",
+			fields: fields{FullName: "paradisisland/maria", DefaultBranch: "main"},
+			branch: workflowBranch,
+			want:   BranchDeleted,
+		},
+		{
+			name:   "When the branch exists and dry run is set",
+			fields: fields{FullName: "paradisisland/maria", DefaultBranch: "main"},
+			branch: workflowBranch,
+			dryRun: true,
+			want:   BranchWouldDelete,
+		},
+		{
+			name:     "When safe mode is set and all commits were made by add-files",
+			fields:   fields{FullName: "paradisisland/maria", DefaultBranch: "main"},
+			branch:   workflowBranch,
+			safeMode: true,
+			want:     BranchDeleted,
+		},
+		{
+			name:     "When safe mode is set and the branch has a foreign commit",
+			fields:   fields{FullName: "paradisisland/sheena", DefaultBranch: "main"},
+			branch:   workflowBranch,
+			safeMode: true,
+			want:     BranchForeignCommit,
+		},
+		{
+			name:     "When safe mode is set and the branch has commits of the token user",
+			fields:   fields{FullName: "paradisisland/trost", DefaultBranch: "main"},
+			branch:   workflowBranch,
+			dryRun:   true,
+			safeMode: true,
+			want:     BranchForeignCommit,
+		},
+		{
+			name:           "When safe mode is set, the token user is trusted and the branch has commits of the token user",
+			fields:         fields{FullName: "paradisisland/trost", DefaultBranch: "main"},
+			branch:         workflowBranch,
+			dryRun:         true,
+			safeMode:       true,
+			trustTokenUser: true,
+			want:           BranchWouldDelete,
+		},
+		{
+			name:     "When safe mode is set and the branch has an open pull request",
+			fields:   fields{FullName: "paradisisland/titanforest", DefaultBranch: "main"},
+			branch:   workflowBranch,
+			safeMode: true,
+			want:     BranchOpenPR,
+		},
+		{
+			name:   "When the branch is protected",
+			fields: fields{FullName: "paradisisland/rose", DefaultBranch: "main"},
+			branch: workflowBranch,
+			want:   BranchProtected,
+		},
+		{
+			name:   "When the branch does not exist",
+			fields: fields{FullName: "paradisisland/shiganshima", DefaultBranch: "main"},
+			branch: workflowBranch,
+			want:   BranchNotFound,
+		},
+		{
+			name:   "When the branch is the default branch",
+			fields: fields{FullName: "paradisisland/maria", DefaultBranch: "main"},
+			branch: "main",
+			want:   BranchDefaultBranch,
+		},
+		{
+			name:   "When the branch cannot be retrieved",
+			fields: fields{FullName: "paradisisland/marley", DefaultBranch: "main"},
+			branch: workflowBranch,
+			want:   BranchFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DryRun = tt.dryRun
+			SafeMode = tt.safeMode
+			TrustTokenUser = tt.trustTokenUser
+			defer func() {
+				DryRun = false
+				SafeMode = false
+				TrustTokenUser = false
+			}()
+
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fields.FullName, DefaultBranch: tt.fields.DefaultBranch}
+			if got := repo.deleteBranchSafely(client, tt.branch); got != tt.want {
+				t.Errorf("Repository.deleteBranchSafely() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_isOwnedCommit(t *testing.T) {
+	commit := func(name string, email string, login string, message string, parents int) ComparedCommit {
+		var c ComparedCommit
+		c.Commit.Author = Identity{Name: name, Email: email}
+		c.Commit.Message = message
+		if len(login) > 0 {
+			c.Author = &Account{Login: login}
+		}
+		for i := 0; i < parents; i++ {
+			c.Parents = append(c.Parents, CommitParent{Sha: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"})
+		}
+		return c
+	}
+
+	tests := []struct {
+		name           string
+		commit         ComparedCommit
+		login          string
+		trustTokenUser bool
+		want           bool
+	}{
+		{
+			name:   "When the commit has the add-files committer identity",
+			commit: commit(committerName, committerEmail, "", "AUTOMATED: commited CodeQL file", 1),
+			login:  "",
+			want:   true,
+		},
+		{
+			name:   "When the commit was made by the token user",
+			commit: commit("Rollout Bot", "12345+rollout-bot@users.noreply.github.com", "rollout-bot", "AUTOMATED: commited CodeQL file", 1),
+			login:  "rollout-bot",
+			want:   false,
+		},
+		{
+			name:           "When the commit was made by the token user and the token user is trusted",
+			commit:         commit("Rollout Bot", "12345+rollout-bot@users.noreply.github.com", "rollout-bot", "AUTOMATED: commited CodeQL file", 1),
+			login:          "rollout-bot",
+			trustTokenUser: true,
+			want:           true,
+		},
+		{
+			name:           "When the token user merged the default branch into the branch and the token user is trusted",
+			commit:         commit("Rollout Bot", "12345+rollout-bot@users.noreply.github.com", "rollout-bot", "Merge branch 'main' into gh-cli/codescanningworkflow", 2),
+			login:          "rollout-bot",
+			trustTokenUser: true,
+			want:           true,
+		},
+		{
+			name:   "When someone else merged the default branch into the branch",
+			commit: commit("Levi Ackerman", "levi@paradisisland", "levi", "Merge branch 'main' into gh-cli/codescanningworkflow", 2),
+			login:  "rollout-bot",
+			want:   false,
+		},
+		{
+			name:           "When the commit was made by someone else and the token user is trusted",
+			commit:         commit("Hange Zoe", "hange@paradisisland", "hange", "Use a larger runner", 1),
+			login:          "rollout-bot",
+			trustTokenUser: true,
+			want:           false,
+		},
+		{
+			name:           "When the token user is unknown",
+			commit:         commit("Hange Zoe", "hange@paradisisland", "", "Use a larger runner", 1),
+			login:          "",
+			trustTokenUser: true,
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TrustTokenUser = tt.trustTokenUser
+			defer func() { TrustTokenUser = false }()
+
+			repo := &Repository{FullName: "paradisisland/trost", DefaultBranch: "main"}
+			if got := repo.isOwnedCommit(tt.commit, tt.login); got != tt.want {
+				t.Errorf("Repository.isOwnedCommit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_listMatchingBranches(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		pattern  string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "When branches match the pattern",
+			fullName: "paradisisland/maria",
+			pattern:  "gh-cli/*",
+			want:     []string{"gh-cli/codescanningworkflow", "gh-cli/dependabot"},
+			wantErr:  false,
+		},
+		{
+			name:     "When the pattern matches nested branches",
+			fullName: "paradisisland/maria",
+			pattern:  "gh-cli/*/branch",
+			want:     []string{"gh-cli/nested/branch"},
+			wantErr:  false,
+		},
+		{
+			name:     "When the repository is invalid",
+			fullName: "paradisisland/marley",
+			pattern:  "gh-cli/*",
+			want:     nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.listMatchingBranches(client, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.listMatchingBranches() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.listMatchingBranches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "When the age is in days", age: "30d", want: 30 * 24 * time.Hour},
+		{name: "When the age is in weeks", age: "2w", want: 14 * 24 * time.Hour},
+		{name: "When the age is in hours", age: "12h", want: 12 * time.Hour},
+		{name: "When the unit is unknown", age: "3m", wantErr: true},
+		{name: "When the number is missing", age: "d", wantErr: true},
+		{name: "When the number is negative", age: "-1d", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAge(tt.age)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseAge() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var DependabotSettingsFile string
+
+// dependabotBranch is the branch the Dependabot configuration is committed to.
+const dependabotBranch = "gh-cli/dependabot"
+
+// DependabotSettings holds the options used for every generated update entry.
+type DependabotSettings struct {
+	Schedule DependabotSchedule         `yaml:"schedule"`
+	Labels   []string                   `yaml:"labels"`
+	Groups   map[string]DependabotGroup `yaml:"groups"`
+}
+
+type DependabotSchedule struct {
+	Interval string `yaml:"interval"`
+	Day      string `yaml:"day,omitempty"`
+	Time     string `yaml:"time,omitempty"`
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+type DependabotGroup struct {
+	DependencyType  string   `yaml:"dependency-type,omitempty"`
+	Patterns        []string `yaml:"patterns,omitempty"`
+	ExcludePatterns []string `yaml:"exclude-patterns,omitempty"`
+	UpdateTypes     []string `yaml:"update-types,omitempty"`
+}
+
+// DependabotUpdate is a single entry of the updates list in dependabot.yml.
+type DependabotUpdate struct {
+	PackageEcosystem string                     `yaml:"package-ecosystem"`
+	Directory        string                     `yaml:"directory"`
+	Schedule         DependabotSchedule         `yaml:"schedule"`
+	Labels           []string                   `yaml:"labels,omitempty"`
+	Groups           map[string]DependabotGroup `yaml:"groups,omitempty"`
+}
+
+type DependabotConfig struct {
+	Version int                `yaml:"version"`
+	Updates []DependabotUpdate `yaml:"updates"`
+}
+
+// dependabotManifests maps manifest file names to their Dependabot package ecosystem.
+var dependabotManifests = map[string]string{
+	"go.mod":           "gomod",
+	"package.json":     "npm",
+	"pom.xml":          "maven",
+	"build.gradle":     "gradle",
+	"build.gradle.kts": "gradle",
+	"requirements.txt": "pip",
+	"Pipfile":          "pip",
+	"pyproject.toml":   "pip",
+	"Gemfile":          "bundler",
+	"composer.json":    "composer",
+	"Cargo.toml":       "cargo",
+	"packages.config":  "nuget",
+	"Dockerfile":       "docker",
+}
+
+func init() {
+	dependabotCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to add the Dependabot configuration to")
+	dependabotCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	dependabotCmd.MarkFlagsMutuallyExclusive("csv", "organization")
+	dependabotCmd.PersistentFlags().StringVarP(&DependabotSettingsFile, "settings", "s", "", "specify the path to a YAML file with the schedule, labels and groups of the update entries")
+	dependabotCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+	dependabotCmd.PersistentFlags().BoolVarP(&Force, "force", "f", false, "replace the Dependabot branch if it already exists")
+}
+
+var dependabotCmd = &cobra.Command{
+	Use:   "dependabot",
+	Short: "Add a Dependabot configuration",
+	Long:  "Add / Update the dependabot.yml file in each repo in organisation, csv file or argument list via a PR, with an update entry for every package manifest found",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		// check if organization or csv file is provided
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		settings, err := loadDependabotSettings(DependabotSettingsFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var pullRequests []string
+		var noManifest []string
+		var upToDate []string
+
+		for _, repo := range repos {
+			log.Printf("Details for Repository: Full Name: %s; Name: %s; Default Branch: %s\n", repo.FullName, repo.Name, repo.DefaultBranch)
+
+			tree, err := repo.listTree(client)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+
+			updates := detectDependabotUpdates(tree, settings)
+			if len(updates) <= 0 {
+				log.Printf("No package manifests found for repository: %s, skipping.\n", repo.FullName)
+				noManifest = append(noManifest, repo.FullName)
+				continue
+			}
+
+			createdPR, err := repo.raiseDependabotPullRequest(client, updates)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			if len(createdPR) <= 0 {
+				upToDate = append(upToDate, repo.FullName)
+				continue
+			}
+			pullRequests = append(pullRequests, createdPR)
+		}
+
+		log.Printf("Number of repos processed: %d\n", len(repos))
+
+		if len(noManifest) > 0 {
+			log.Printf("Repositories with no package manifests: %d\n", len(noManifest))
+			for _, repo := range noManifest {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(upToDate) > 0 {
+			log.Printf("Repositories with an up to date Dependabot configuration: %d\n", len(upToDate))
+			for _, repo := range upToDate {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(pullRequests) > 0 {
+			log.Printf("Pull requests raised: %d\n", len(pullRequests))
+			for _, pr := range pullRequests {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+
+		log.Printf("Finished adding Dependabot configuration! \n")
+	},
+}
+
+func loadDependabotSettings(DependabotSettingsFile string) (DependabotSettings, error) {
+	settings := DependabotSettings{Schedule: DependabotSchedule{Interval: "weekly"}}
+	if len(DependabotSettingsFile) <= 0 {
+		return settings, nil
+	}
+
+	content, err := os.ReadFile(DependabotSettingsFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to read Dependabot settings file %s\n", DependabotSettingsFile)
+		return settings, err
+	}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		log.Printf("ERROR: Unable to parse Dependabot settings file %s\n", DependabotSettingsFile)
+		return settings, err
+	}
+	if len(settings.Schedule.Interval) <= 0 {
+		settings.Schedule.Interval = "weekly"
+	}
+	return settings, nil
+}
+
+// dependabotEcosystem returns the package ecosystem of a file in the tree, or an empty string.
+func dependabotEcosystem(file string) string {
+	if path.Dir(file) == ".github/workflows" && (strings.HasSuffix(file, ".yml") || strings.HasSuffix(file, ".yaml")) {
+		return "github-actions"
+	}
+
+	name := path.Base(file)
+	if ecosystem, ok := dependabotManifests[name]; ok {
+		return ecosystem
+	}
+	if strings.HasSuffix(name, ".csproj") {
+		return "nuget"
+	}
+	if strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile") || strings.HasSuffix(name, ".dockerfile") {
+		return "docker"
+	}
+	return ""
+}
+
+// dependabotSkippedDirectories hold copies of dependencies whose manifests must not get their own update entry.
+var dependabotSkippedDirectories = map[string]bool{
+	"node_modules":     true,
+	"bower_components": true,
+	"vendor":           true,
+	"third_party":      true,
+	"Pods":             true,
+	"Carthage":         true,
+	"venv":             true,
+	".venv":            true,
+	"site-packages":    true,
+}
+
+// isVendored reports whether the file is inside a directory of vendored dependencies.
+func isVendored(file string) bool {
+	for _, segment := range strings.Split(path.Dir(file), "/") {
+		if dependabotSkippedDirectories[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDependabotUpdates returns one update entry per ecosystem and directory with a package manifest.
+func detectDependabotUpdates(tree []TreeEntry, settings DependabotSettings) []DependabotUpdate {
+	seen := map[string]bool{}
+	var updates []DependabotUpdate
+	for _, entry := range tree {
+		if entry.Type != "blob" || isVendored(entry.Path) {
+			continue
+		}
+		ecosystem := dependabotEcosystem(entry.Path)
+		if len(ecosystem) <= 0 {
+			continue
+		}
+
+		// workflows are updated from the root of the repository
+		directory := "/"
+		if ecosystem != "github-actions" && path.Dir(entry.Path) != "." {
+			directory = "/" + path.Dir(entry.Path)
+		}
+
+		key := ecosystem + ":" + directory
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		updates = append(updates, DependabotUpdate{
+			PackageEcosystem: ecosystem,
+			Directory:        directory,
+			Schedule:         settings.Schedule,
+			Labels:           settings.Labels,
+			Groups:           settings.Groups,
+		})
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool {
+		if updates[i].PackageEcosystem != updates[j].PackageEcosystem {
+			return updates[i].PackageEcosystem < updates[j].PackageEcosystem
+		}
+		return updates[i].Directory < updates[j].Directory
+	})
+	return updates
+}
+
+func generateDependabotConfig(updates []DependabotUpdate) ([]byte, error) {
+	return encodeYaml(DependabotConfig{Version: 2, Updates: updates})
+}
+
+// mergeDependabotConfig adds the updates that are missing from the existing dependabot.yml, keeping its entries and comments.
+// It returns nil when no update is missing.
+func mergeDependabotConfig(existing []byte, updates []DependabotUpdate) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(existing, &document); err != nil {
+		log.Printf("ERROR: Unable to parse the existing Dependabot configuration\n")
+		return nil, err
+	}
+	if len(document.Content) <= 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the existing Dependabot configuration is not a YAML mapping")
+	}
+	root := document.Content[0]
+
+	updatesNode := mappingValue(root, "updates")
+	if updatesNode == nil {
+		updatesNode = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "updates"}, updatesNode)
+	}
+	if updatesNode.Kind != yaml.SequenceNode {
+		return nil, errors.New("the updates of the existing Dependabot configuration are not a list")
+	}
+
+	configured := map[string]bool{}
+	for _, entry := range updatesNode.Content {
+		ecosystem := mappingValue(entry, "package-ecosystem")
+		if ecosystem == nil {
+			continue
+		}
+		var directories []string
+		if directory := mappingValue(entry, "directory"); directory != nil {
+			directories = append(directories, directory.Value)
+		}
+		if list := mappingValue(entry, "directories"); list != nil {
+			for _, directory := range list.Content {
+				directories = append(directories, directory.Value)
+			}
+		}
+		for _, directory := range directories {
+			configured[ecosystem.Value+":"+normalizeDirectory(directory)] = true
+		}
+	}
+
+	added := 0
+	for _, update := range updates {
+		if configured[update.PackageEcosystem+":"+normalizeDirectory(update.Directory)] {
+			continue
+		}
+		var entry yaml.Node
+		if err := entry.Encode(update); err != nil {
+			return nil, err
+		}
+		updatesNode.Content = append(updatesNode.Content, &entry)
+		added++
+	}
+	if added == 0 {
+		return nil, nil
+	}
+	log.Printf("Adding %d update entries to the existing Dependabot configuration\n", added)
+	return encodeYaml(&document)
+}
+
+// mappingValue returns the value of the key in a YAML mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func normalizeDirectory(directory string) string {
+	return "/" + strings.Trim(directory, "/")
+}
+
+func encodeYaml(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		log.Printf("ERROR: Unable to encode YAML\n")
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// raiseDependabotPullRequest opens a PR that adds or updates dependabot.yml.
+// It returns an empty string when the existing configuration already covers every update.
+func (repo *Repository) raiseDependabotPullRequest(client Client, updates []DependabotUpdate) (string, error) {
+	configPath := ".github/dependabot.yml"
+	existing, sha, err := repo.getFileContent(client, configPath, repo.DefaultBranch)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		existing, sha, err = repo.getFileContent(client, ".github/dependabot.yaml", repo.DefaultBranch)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			configPath = ".github/dependabot.yaml"
+		}
+	}
+
+	var content []byte
+	if existing != nil {
+		content, err = mergeDependabotConfig(existing, updates)
+		if err != nil {
+			return "", err
+		}
+		if content == nil {
+			log.Printf("Dependabot configuration already covers every package manifest for repository: %s, skipping.\n", repo.FullName)
+			return "", nil
+		}
+	} else {
+		content, err = generateDependabotConfig(updates)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	newbranchref, err := repo.createOrReplaceBranch(client, dependabotBranch)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Ref created succesfully at : %s\n", newbranchref)
+
+	if _, err := repo.commitFile(client, dependabotBranch, configPath, content, sha, "AUTOMATED: commited Dependabot configuration"); err != nil {
+		return "", err
+	}
+
+	createdPR, err := repo.openPullRequest(client, dependabotBranch, "Automated PR: Dependabot configuration added", dependabotPullRequestBody)
+	if err != nil {
+		return "", err
+	}
+	if len(createdPR) <= 0 {
+		return "", errors.New("Something went wrong when creating new pull request")
+	}
+	log.Printf("Successfully raised pull request %s on branch %s in repository %s\n", createdPR, newbranchref, repo.FullName)
+	return createdPR, nil
+}
+
+var dependabotPullRequestBody = strings.Replace(`
+	## What does this PR do?
+
+	This is an automated PR created by your security team to keep the dependencies of your repository up to date with Dependabot. It configures version updates for every package manifest found in the repository.
+
+	For more information on Dependabot, please see [here](https://docs.github.com/en/code-security/dependabot/dependabot-version-updates/configuring-dependabot-version-updates).
+
+	## What happens after I merge this PR?
+
+	Dependabot will open PRs to update outdated dependencies on the configured schedule. Existing entries in your Dependabot configuration are kept as they are.
+
+	If you require any further assistance, please contact the security team.
+	`, "\n\t", "\n", -1)
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_dependabotEcosystem(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "When the file is a go.mod", file: "go.mod", want: "gomod"},
+		{name: "When the file is a nested package.json", file: "web/package.json", want: "npm"},
+		{name: "When the file is a csproj", file: "src/App/App.csproj", want: "nuget"},
+		{name: "When the file is a suffixed Dockerfile", file: "build/Dockerfile.release", want: "docker"},
+		{name: "When the file is a workflow", file: ".github/workflows/ci.yml", want: "github-actions"},
+		{name: "When the file is not a manifest", file: "README.md", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dependabotEcosystem(tt.file); got != tt.want {
+				t.Errorf("dependabotEcosystem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_detectDependabotUpdates(t *testing.T) {
+	settings := DependabotSettings{Schedule: DependabotSchedule{Interval: "daily"}, Labels: []string{"dependencies"}}
+	tree := []TreeEntry{
+		{Path: ".github/workflows/ci.yml", Type: "blob"},
+		{Path: ".github/workflows/codeql.yml", Type: "blob"},
+		{Path: "Dockerfile", Type: "blob"},
+		{Path: "go.mod", Type: "blob"},
+		{Path: "vendor/github.com/spf13/cobra/go.mod", Type: "blob"},
+		{Path: "web", Type: "tree"},
+		{Path: "web/package.json", Type: "blob"},
+		{Path: "web/node_modules/lodash/package.json", Type: "blob"},
+	}
+	want := []DependabotUpdate{
+		{PackageEcosystem: "docker", Directory: "/", Schedule: settings.Schedule, Labels: settings.Labels},
+		{PackageEcosystem: "github-actions", Directory: "/", Schedule: settings.Schedule, Labels: settings.Labels},
+		{PackageEcosystem: "gomod", Directory: "/", Schedule: settings.Schedule, Labels: settings.Labels},
+		{PackageEcosystem: "npm", Directory: "/web", Schedule: settings.Schedule, Labels: settings.Labels},
+	}
+
+	if got := detectDependabotUpdates(tree, settings); !reflect.DeepEqual(got, want) {
+		t.Errorf("detectDependabotUpdates() = %v, want %v", got, want)
+	}
+}
+
+func Test_generateDependabotConfig(t *testing.T) {
+	updates := []DependabotUpdate{
+		{PackageEcosystem: "gomod", Directory: "/", Schedule: DependabotSchedule{Interval: "weekly", Day: "monday"}, Labels: []string{"dependencies"}},
+	}
+	want := `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+      day: monday
+    labels:
+      - dependencies
+`
+
+	got, err := generateDependabotConfig(updates)
+	if err != nil {
+		t.Fatalf("generateDependabotConfig() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("generateDependabotConfig() = %v, want %v", string(got), want)
+	}
+}
+
+func Test_mergeDependabotConfig(t *testing.T) {
+	updates := []DependabotUpdate{
+		{PackageEcosystem: "gomod", Directory: "/", Schedule: DependabotSchedule{Interval: "weekly"}},
+		{PackageEcosystem: "npm", Directory: "/web", Schedule: DependabotSchedule{Interval: "weekly"}},
+	}
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "When an ecosystem is missing it is appended",
+			existing: `version: 2
+updates:
+  # keep Go modules current
+  - package-ecosystem: gomod
+    directory: "/"
+    schedule:
+      interval: daily
+`,
+			want: `version: 2
+updates:
+  # keep Go modules current
+  - package-ecosystem: gomod
+    directory: "/"
+    schedule:
+      interval: daily
+  - package-ecosystem: npm
+    directory: /web
+    schedule:
+      interval: weekly
+`,
+			wantErr: false,
+		},
+		{
+			name: "When every ecosystem is configured",
+			existing: `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: daily
+  - package-ecosystem: npm
+    directories: ["/web/", "/api"]
+    schedule:
+      interval: daily
+`,
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name:     "When the existing configuration is not a mapping",
+			existing: "- gomod\n",
+			want:     "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeDependabotConfig([]byte(tt.existing), updates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mergeDependabotConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeDependabotConfig() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_raiseDependabotPullRequest(t *testing.T) {
+	updates := []DependabotUpdate{
+		{PackageEcosystem: "gomod", Directory: "/", Schedule: DependabotSchedule{Interval: "weekly"}},
+	}
+	tests := []struct {
+		name     string
+		fullName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "When the repository has no Dependabot configuration",
+			fullName: "paradisisland/maria",
+			want:     "https://github.com/paradisisland/maria/pull/",
+			wantErr:  false,
+		},
+		{
+			name:     "When the Dependabot configuration already covers every manifest",
+			fullName: "paradisisland/shiganshima",
+			want:     "",
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.raiseDependabotPullRequest(client, updates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.raiseDependabotPullRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.raiseDependabotPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
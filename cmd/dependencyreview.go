@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var FailOnSeverity string
+var AllowLicenses []string
+var CommentSummary string
+
+// dependencyReviewBranch is the branch the dependency review workflow is committed to.
+const dependencyReviewBranch = "gh-cli/dependencyreview"
+
+// dependencyReviewPath is where the dependency review workflow is committed.
+const dependencyReviewPath = ".github/workflows/dependency-review.yml"
+
+// emptyAllowLicenses matches the allow-licenses line of a template rendered without licenses.
+var emptyAllowLicenses = regexp.MustCompile(`(?m)^[ \t]*allow-licenses:[ \t]*\r?\n`)
+
+func init() {
+	dependencyReviewCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to add the dependency review workflow to")
+	dependencyReviewCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	dependencyReviewCmd.MarkFlagsMutuallyExclusive("csv", "organization")
+	dependencyReviewCmd.PersistentFlags().StringVarP(&TemplateFile, "template", "t", "", "specify the path to the dependency review workflow template file")
+	dependencyReviewCmd.MarkPersistentFlagRequired("template")
+	dependencyReviewCmd.PersistentFlags().StringVar(&FailOnSeverity, "fail-on-severity", "high", "specify the lowest vulnerability severity that fails the review: low, moderate, high or critical")
+	dependencyReviewCmd.PersistentFlags().StringSliceVar(&AllowLicenses, "allow-licenses", nil, "specify the licenses dependencies may use e.g. MIT,Apache-2.0")
+	dependencyReviewCmd.PersistentFlags().StringVar(&CommentSummary, "comment-summary", "on-failure", "specify when the review summary is commented on the PR: always, on-failure or never")
+	dependencyReviewCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+	dependencyReviewCmd.PersistentFlags().BoolVarP(&Force, "force", "f", false, "update an existing dependency review workflow file and replace the branch if it already exists")
+}
+
+var dependencyReviewCmd = &cobra.Command{
+	Use:   "dependency-review",
+	Short: "Add a dependency review workflow",
+	Long:  "Add / Update the dependency-review.yml workflow in each repo with package manifests in organisation, csv file or argument list via a PR",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		// check if organization or csv file is provided
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		if !containsFold([]string{"low", "moderate", "high", "critical"}, FailOnSeverity) {
+			log.Fatalf("ERROR: Unknown severity %s, must be low, moderate, high or critical\n", FailOnSeverity)
+		} else if !containsFold([]string{"always", "on-failure", "never"}, CommentSummary) {
+			log.Fatalf("ERROR: Unknown comment summary %s, must be always, on-failure or never\n", CommentSummary)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var pullRequests []string
+		var noManifest []string
+		var existing []string
+
+		for _, repo := range repos {
+			log.Printf("Details for Repository: Full Name: %s; Name: %s; Default Branch: %s\n", repo.FullName, repo.Name, repo.DefaultBranch)
+
+			tree, err := repo.listTree(client)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			if !hasDependencyManifest(tree) {
+				log.Printf("No package manifests found for repository: %s, skipping.\n", repo.FullName)
+				noManifest = append(noManifest, repo.FullName)
+				continue
+			}
+
+			createdPR, err := repo.raiseDependencyReviewPullRequest(client)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			if len(createdPR) <= 0 {
+				existing = append(existing, repo.FullName)
+				continue
+			}
+			pullRequests = append(pullRequests, createdPR)
+		}
+
+		log.Printf("Number of repos processed: %d\n", len(repos))
+
+		if len(noManifest) > 0 {
+			log.Printf("Repositories with no package manifests: %d\n", len(noManifest))
+			for _, repo := range noManifest {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(existing) > 0 {
+			log.Printf("Repositories with a dependency review workflow already: %d\n", len(existing))
+			for _, repo := range existing {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(pullRequests) > 0 {
+			log.Printf("Pull requests raised: %d\n", len(pullRequests))
+			for _, pr := range pullRequests {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+
+		log.Printf("Finished adding dependency review workflow! \n")
+	},
+}
+
+// hasDependencyManifest reports whether the tree has a package manifest the dependency graph can read.
+func hasDependencyManifest(tree []TreeEntry) bool {
+	for _, entry := range tree {
+		if entry.Type != "blob" || isVendored(entry.Path) {
+			continue
+		}
+		ecosystem := dependabotEcosystem(entry.Path)
+		if len(ecosystem) > 0 && ecosystem != "docker" && ecosystem != "github-actions" {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyReviewValues are the template placeholders of the dependency review workflow.
+func dependencyReviewValues() map[string]string {
+	return map[string]string{
+		"FailOnSeverity": strings.ToLower(FailOnSeverity),
+		"AllowLicenses":  strings.Join(AllowLicenses, ", "),
+		"CommentSummary": strings.ToLower(CommentSummary),
+	}
+}
+
+// renderDependencyReviewWorkflow renders and validates the dependency review workflow for the repository.
+// Without licenses the allow-licenses key is left out, because an empty value is read as null and fails the action.
+func (repo *Repository) renderDependencyReviewWorkflow() ([]byte, error) {
+	workflowFile, err := repo.renderTemplateFile(TemplateFile, dependencyReviewValues())
+	if err != nil {
+		return nil, err
+	}
+	if len(AllowLicenses) <= 0 {
+		workflowFile = emptyAllowLicenses.ReplaceAll(workflowFile, nil)
+	}
+
+	if err := validateWorkflow(workflowFile); err != nil {
+		log.Printf("ERROR: The dependency review workflow for repository %s is invalid: %s\n", repo.FullName, err)
+		return nil, err
+	}
+	return workflowFile, nil
+}
+
+// raiseDependencyReviewPullRequest opens a PR that adds the dependency review workflow.
+// It returns an empty string when the workflow already exists and the force flag is not set.
+func (repo *Repository) raiseDependencyReviewPullRequest(client Client) (string, error) {
+	current, sha, err := repo.getFileContent(client, dependencyReviewPath, repo.DefaultBranch)
+	if err != nil {
+		return "", err
+	}
+	if current != nil && !Force {
+		log.Printf("Dependency review workflow already exists for this repository: %s, skipping.\n", repo.FullName)
+		return "", nil
+	}
+
+	workflowFile, err := repo.renderDependencyReviewWorkflow()
+	if err != nil {
+		return "", err
+	}
+
+	newbranchref, err := repo.createOrReplaceBranch(client, dependencyReviewBranch)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Ref created succesfully at : %s\n", newbranchref)
+
+	if _, err := repo.commitFile(client, dependencyReviewBranch, dependencyReviewPath, workflowFile, sha, "AUTOMATED: commited dependency review workflow"); err != nil {
+		return "", err
+	}
+
+	createdPR, err := repo.openPullRequest(client, dependencyReviewBranch, "Automated PR: dependency review workflow added", dependencyReviewPullRequestBody)
+	if err != nil {
+		return "", err
+	}
+	if len(createdPR) <= 0 {
+		return "", errors.New("Something went wrong when creating new pull request")
+	}
+	log.Printf("Successfully raised pull request %s on branch %s in repository %s\n", createdPR, newbranchref, repo.FullName)
+	return createdPR, nil
+}
+
+var dependencyReviewPullRequestBody = strings.Replace(`
+	## What does this PR do?
+
+	This is an automated PR created by your security team to add dependency review to your repository. On every PR, it checks the dependencies that are added or changed for known vulnerabilities and disallowed licenses.
+
+	For more information on dependency review, please see [here](https://docs.github.com/en/code-security/supply-chain-security/understanding-your-software-supply-chain/about-dependency-review).
+
+	## What happens after I merge this PR?
+
+	PRs that add vulnerable dependencies or dependencies with a license that is not allowed will fail the dependency review check, and a summary of the findings will be commented on the PR.
+
+	If you require any further assistance, please contact the security team.
+	`, "\n\t", "\n", -1)
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_hasDependencyManifest(t *testing.T) {
+	tests := []struct {
+		name string
+		tree []TreeEntry
+		want bool
+	}{
+		{
+			name: "When the repository has a package manifest",
+			tree: []TreeEntry{{Path: "web", Type: "tree"}, {Path: "web/package.json", Type: "blob"}},
+			want: true,
+		},
+		{
+			name: "When the repository only has workflows and a Dockerfile",
+			tree: []TreeEntry{{Path: ".github/workflows/ci.yml", Type: "blob"}, {Path: "Dockerfile", Type: "blob"}},
+			want: false,
+		},
+		{
+			name: "When the only manifests are vendored",
+			tree: []TreeEntry{{Path: "vendor/github.com/spf13/cobra/go.mod", Type: "blob"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasDependencyManifest(tt.tree); got != tt.want {
+				t.Errorf("hasDependencyManifest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_renderDependencyReviewWorkflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowLicenses []string
+		want          []string
+		wantMissing   string
+	}{
+		{
+			name:          "When licenses are allowed",
+			allowLicenses: []string{"MIT", "Apache-2.0"},
+			want: []string{
+				`branches: [ "trunk" ]`,
+				"fail-on-severity: moderate",
+				"allow-licenses: MIT, Apache-2.0",
+				"comment-summary-in-pr: always",
+			},
+		},
+		{
+			name:          "When no licenses are given the key is left out",
+			allowLicenses: nil,
+			want:          []string{"fail-on-severity: moderate", "comment-summary-in-pr: always"},
+			wantMissing:   "allow-licenses",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TemplateFile = "../examples/dependency-review-template.yml"
+			FailOnSeverity, AllowLicenses, CommentSummary = "Moderate", tt.allowLicenses, "always"
+			defer func() {
+				TemplateFile = ""
+				FailOnSeverity, AllowLicenses, CommentSummary = "high", nil, "on-failure"
+			}()
+
+			repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "trunk"}
+			got, err := repo.renderDependencyReviewWorkflow()
+			if err != nil {
+				t.Fatalf("Repository.renderDependencyReviewWorkflow() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("Repository.renderDependencyReviewWorkflow() = %v, want it to contain %v", string(got), want)
+				}
+			}
+			if len(tt.wantMissing) > 0 && strings.Contains(string(got), tt.wantMissing) {
+				t.Errorf("Repository.renderDependencyReviewWorkflow() = %v, want it not to contain %v", string(got), tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestRepository_raiseDependencyReviewPullRequest(t *testing.T) {
+	invalidTemplate := filepath.Join(t.TempDir(), "dependency-review.yml")
+	if err := os.WriteFile(invalidTemplate, []byte("name: Dependency Review\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		fullName     string
+		templateFile string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "When the repository has no dependency review workflow",
+			fullName:     "paradisisland/maria",
+			templateFile: "../examples/dependency-review-template.yml",
+			want:         "https://github.com/paradisisland/maria/pull/",
+			wantErr:      false,
+		},
+		{
+			name:         "When the repository already has a dependency review workflow",
+			fullName:     "paradisisland/shiganshima",
+			templateFile: "../examples/dependency-review-template.yml",
+			want:         "",
+			wantErr:      false,
+		},
+		{
+			name:         "When the template renders an invalid workflow",
+			fullName:     "paradisisland/maria",
+			templateFile: invalidTemplate,
+			want:         "",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			TemplateFile = tt.templateFile
+			defer func() { TemplateFile = "" }()
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.raiseDependencyReviewPullRequest(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.raiseDependencyReviewPullRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.raiseDependencyReviewPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
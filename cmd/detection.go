@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var CentralWorkflow string
+var AnalysisMaxAge string
+
+// isCodeqlWorkflow reports whether the workflow runs CodeQL, either through the CodeQL action or by calling the central reusable workflow.
+func isCodeqlWorkflow(content []byte, centralWorkflow string) (bool, error) {
+	type Workflow struct {
+		Jobs map[string]struct {
+			Uses  string `yaml:"uses"`
+			Steps []struct {
+				Uses string `yaml:"uses"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+
+	var workflow Workflow
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return false, err
+	}
+
+	centralWorkflow = strings.Split(centralWorkflow, "@")[0]
+	for _, job := range workflow.Jobs {
+		if len(job.Uses) > 0 {
+			reusableWorkflow := strings.Split(job.Uses, "@")[0]
+			if len(centralWorkflow) > 0 && strings.EqualFold(reusableWorkflow, centralWorkflow) {
+				return true, nil
+			}
+			if strings.Contains(strings.ToLower(reusableWorkflow), "codeql") {
+				return true, nil
+			}
+		}
+
+		for _, step := range job.Steps {
+			if strings.HasPrefix(step.Uses, "github/codeql-action/") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// findCodeqlWorkflows returns the paths of the workflows on the default branch that run CodeQL.
+func (repo *Repository) findCodeqlWorkflows(client Client) ([]string, error) {
+	type Content struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+		Type string `json:"type"`
+	}
+
+	var contents []Content
+	requestPath := fmt.Sprintf("repos/%s/contents/.github/workflows?ref=%s", repo.FullName, repo.DefaultBranch)
+	statusCode, _, err := callApi(client, requestPath, &contents, GET)
+	if statusCode == 404 {
+		log.Printf("No workflows found for repo: %s\n", repo.FullName)
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("ERROR: Unable to list workflows for repository: %s\n", repo.FullName)
+		return nil, err
+	}
+
+	var workflows []string
+	for _, content := range contents {
+		if content.Type != "file" || !(strings.HasSuffix(content.Name, ".yml") || strings.HasSuffix(content.Name, ".yaml")) {
+			continue
+		}
+
+		workflow, _, err := repo.getFileContent(client, content.Path, repo.DefaultBranch)
+		if err != nil {
+			return workflows, err
+		}
+
+		isCodeql, err := isCodeqlWorkflow(workflow, CentralWorkflow)
+		if err != nil {
+			log.Printf("WARN: Unable to parse workflow %s in repository %s: %s\n", content.Path, repo.FullName, err)
+			continue
+		}
+		if isCodeql {
+			log.Printf("CodeQL workflow found for repo %s: %s\n", repo.FullName, content.Path)
+			workflows = append(workflows, content.Path)
+		}
+	}
+	return workflows, nil
+}
+
+// getLastCodeqlAnalysis returns when CodeQL results were last uploaded, or nil if they never were.
+func (repo *Repository) getLastCodeqlAnalysis(client Client) (*time.Time, error) {
+	type Analysis struct {
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	var analyses []Analysis
+	requestPath := fmt.Sprintf("repos/%s/code-scanning/analyses?tool_name=CodeQL&per_page=1", repo.FullName)
+	statusCode, _, err := callApi(client, requestPath, &analyses, GET)
+	if statusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("ERROR: Unable to get code scanning analyses for repository: %s\n", repo.FullName)
+		return nil, err
+	}
+
+	if len(analyses) == 0 {
+		return nil, nil
+	}
+	return &analyses[0].CreatedAt, nil
+}
+
+// detectExistingCodeql looks for CodeQL workflows under other filenames than codeql.yml and for recent CodeQL results.
+// It returns a description of what was found, or an empty string, and the paths of the CodeQL workflows it found.
+func (repo *Repository) detectExistingCodeql(client Client) (string, []string, error) {
+	workflows, err := repo.findCodeqlWorkflows(client)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(workflows) > 0 {
+		return fmt.Sprintf("CodeQL workflow %s", strings.Join(workflows, ", ")), workflows, nil
+	}
+
+	if len(AnalysisMaxAge) <= 0 {
+		return "", nil, nil
+	}
+	maxAge, err := parseAge(AnalysisMaxAge)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lastAnalysis, err := repo.getLastCodeqlAnalysis(client)
+	if err != nil {
+		return "", nil, err
+	}
+	if lastAnalysis != nil && time.Since(*lastAnalysis) < maxAge {
+		return fmt.Sprintf("CodeQL results uploaded on %s", lastAnalysis.Format(time.RFC3339)), nil, nil
+	}
+	return "", nil, nil
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_isCodeqlWorkflow(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		centralWorkflow string
+		want            bool
+		wantErr         bool
+	}{
+		{
+			name: "When a step uses the CodeQL action",
+			content: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: github/codeql-action/init@v3
+`,
+			want: true,
+		},
+		{
+			name: "When a job calls the central reusable workflow",
+			content: `on: push
+jobs:
+  scan:
+    uses: paradisisland/security/.github/workflows/scan.yml@v2
+`,
+			centralWorkflow: "paradisisland/security/.github/workflows/scan.yml@main",
+			want:            true,
+		},
+		{
+			name: "When a job calls a reusable CodeQL workflow",
+			content: `on: push
+jobs:
+  scan:
+    uses: paradisisland/security/.github/workflows/codeql.yml@main
+`,
+			want: true,
+		},
+		{
+			name: "When a job calls another reusable workflow",
+			content: `on: push
+jobs:
+  scan:
+    uses: paradisisland/security/.github/workflows/scan.yml@main
+`,
+			want: false,
+		},
+		{
+			name: "When the workflow does not run CodeQL",
+			content: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`,
+			want: false,
+		},
+		{
+			name:    "When the workflow is not valid YAML",
+			content: "jobs: [",
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isCodeqlWorkflow([]byte(tt.content), tt.centralWorkflow)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("isCodeqlWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("isCodeqlWorkflow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_findCodeqlWorkflows(t *testing.T) {
+	tests := []struct {
+		name            string
+		fullName        string
+		centralWorkflow string
+		want            []string
+		wantErr         bool
+	}{
+		{
+			name:     "When a workflow under another filename uses the CodeQL action",
+			fullName: "paradisisland/maria",
+			want:     []string{".github/workflows/codeql-analysis.yml"},
+			wantErr:  false,
+		},
+		{
+			name:            "When a workflow calls the central reusable workflow",
+			fullName:        "paradisisland/rose",
+			centralWorkflow: "paradisisland/security/.github/workflows/scan.yml",
+			want:            []string{".github/workflows/security.yaml"},
+			wantErr:         false,
+		},
+		{
+			name:     "When no workflow runs CodeQL",
+			fullName: "paradisisland/shiganshima",
+			want:     nil,
+			wantErr:  false,
+		},
+		{
+			name:     "When the repository has no workflows",
+			fullName: "paradisisland/marley",
+			want:     nil,
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			CentralWorkflow = tt.centralWorkflow
+			defer func() { CentralWorkflow = "" }()
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.findCodeqlWorkflows(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.findCodeqlWorkflows() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.findCodeqlWorkflows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getLastCodeqlAnalysis(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     *time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "When CodeQL results have been uploaded",
+			fullName: "paradisisland/maria",
+			want:     func() *time.Time { t := time.Date(2023, 1, 19, 11, 21, 34, 0, time.UTC); return &t }(),
+			wantErr:  false,
+		},
+		{
+			name:     "When there are no analyses",
+			fullName: "paradisisland/shiganshima",
+			want:     nil,
+			wantErr:  false,
+		},
+		{
+			name:     "When no analysis has been found",
+			fullName: "paradisisland/rose",
+			want:     nil,
+			wantErr:  false,
+		},
+		{
+			name:     "When code scanning is not available",
+			fullName: "paradisisland/marley",
+			want:     nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.getLastCodeqlAnalysis(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getLastCodeqlAnalysis() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("Repository.getLastCodeqlAnalysis() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var PostComments bool
+var BuildReportFile string
+
+// FailureSignature describes a known cause of a failing CodeQL job and how to fix it.
+type FailureSignature struct {
+	Name        string
+	Title       string
+	Pattern     *regexp.Regexp
+	Suggestion  string
+	ManualBuild bool
+}
+
+var failureSignatures = []FailureSignature{
+	{
+		Name:        "autobuild-failed",
+		Title:       "Autobuild failed",
+		Pattern:     regexp.MustCompile(`(?i)we were unable to automatically build your code|could not auto-detect a suitable build method|autobuild(er)? (failed|exited with)`),
+		Suggestion:  "CodeQL was unable to build this repository automatically. Replace the `autobuild` step with the commands you use to build the project and set `build-mode: manual` for the compiled languages. See [here](https://docs.github.com/en/code-security/code-scanning/creating-an-advanced-setup-for-code-scanning/codeql-code-scanning-for-compiled-languages) for more information.",
+		ManualBuild: true,
+	},
+	{
+		Name:        "no-source-code",
+		Title:       "No source code seen",
+		Pattern:     regexp.MustCompile(`(?i)no source code was seen during the build|but could not process any of it|did not detect any code written in languages supported by codeql`),
+		Suggestion:  "CodeQL did not see any source code while the database was created. Make sure the build step compiles the code from scratch without using a cache, or remove the language from the analysis if it is not used in this repository.",
+		ManualBuild: true,
+	},
+	{
+		Name:       "out-of-memory",
+		Title:      "Out of memory",
+		Pattern:    regexp.MustCompile(`(?i)out of memory|outofmemoryerror|java heap space|exit code 137|oom-?kill`),
+		Suggestion: "The analysis ran out of memory. Run the job on a larger runner, or lower the memory used by the build and pass a `ram` value to the `analyze` step.",
+	},
+	{
+		Name:       "unsupported-runner",
+		Title:      "Unsupported runner",
+		Pattern:    regexp.MustCompile(`(?i)no runner matching the specified labels|is not supported on this (runner|platform|operating system)|unsupported (runner|platform|operating system)`),
+		Suggestion: "The job could not run on the selected runner. Change `runs-on` to a runner that is available to this repository and supported by CodeQL.",
+	},
+}
+
+// Diagnosis is the result of matching a failed CodeQL job against the known failure signatures.
+type Diagnosis struct {
+	Repository  string `json:"repository"`
+	PullRequest string `json:"pull_request"`
+	Job         string `json:"job"`
+	JobURL      string `json:"job_url"`
+	Signature   string `json:"signature"`
+	Suggestion  string `json:"suggestion,omitempty"`
+	ManualBuild bool   `json:"manual_build"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+func init() {
+	diagnoseCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to diagnose rollout pull requests for")
+	diagnoseCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	diagnoseCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path to the JSON report of a previous code-scanning run")
+	diagnoseCmd.MarkFlagsMutuallyExclusive("csv", "organization", "report")
+	diagnoseCmd.PersistentFlags().BoolVar(&PostComments, "comment", false, "comment on the pull request with a suggested fix for each diagnosed failure")
+	diagnoseCmd.PersistentFlags().StringVar(&BuildReportFile, "build-report", "", "specify the path where a csv file of repositories needing manual build commands will be saved")
+	diagnoseCmd.PersistentFlags().StringVarP(&OutputFormat, "format", "F", "table", "specify the output format: table or json")
+	diagnoseCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Diagnose failing CodeQL checks on rollout pull requests",
+	Long:  "Match the logs of failed CodeQL jobs on open rollout pull requests against known failure signatures and suggest a fix",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stderr)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(ReportFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag, csv flag or report flag must be provided")
+		} else if (len(Organization) > 0 || len(CsvFile) > 0 || len(ReportFile) > 0) && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both input flags and repository names as arguments")
+		}
+
+		if OutputFormat != "table" && OutputFormat != "json" {
+			log.Fatalf("ERROR: Unknown output format %s, must be table or json\n", OutputFormat)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		var repos []Repository
+		if len(ReportFile) > 0 {
+			repos, err = loadReportRepositories(client, ReportFile)
+		} else {
+			repos, err = loadRepositories(client, Organization, CsvFile, args)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var diagnoses []Diagnosis
+		var manualBuild []string
+
+		for _, repo := range repos {
+			pullRequests, err := repo.listPullRequests(client, workflowBranch, "open")
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+
+			needsManualBuild := false
+			for _, pullRequest := range pullRequests {
+				results, err := repo.diagnosePullRequest(client, pullRequest)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+
+				for i := range results {
+					if results[i].ManualBuild {
+						needsManualBuild = true
+					}
+					if PostComments && len(results[i].Suggestion) > 0 {
+						results[i].Comment, err = repo.commentDiagnosis(client, pullRequest.Number, results[i])
+						if err != nil {
+							Errors[repo.FullName] = err
+						}
+					}
+				}
+				diagnoses = append(diagnoses, results...)
+			}
+
+			if needsManualBuild {
+				manualBuild = append(manualBuild, repo.FullName)
+			}
+		}
+
+		if OutputFormat == "json" {
+			if err := printJSON(os.Stdout, diagnoses); err != nil {
+				log.Fatalln(err)
+			}
+		} else {
+			var rows [][]string
+			for _, diagnosis := range diagnoses {
+				rows = append(rows, []string{diagnosis.Repository, diagnosis.PullRequest, diagnosis.Job, diagnosis.Signature})
+			}
+			if err := printTable(os.Stdout, []string{"REPOSITORY", "PULL REQUEST", "JOB", "FAILURE"}, rows); err != nil {
+				log.Fatalln(err)
+			}
+		}
+
+		if len(manualBuild) > 0 {
+			log.Printf("Repositories needing manual build commands: %d\n", len(manualBuild))
+			for _, repo := range manualBuild {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(BuildReportFile) > 0 {
+			if err := writeRepositoryCsv(BuildReportFile, manualBuild); err != nil {
+				log.Fatalln(err)
+			}
+			log.Printf("Repositories needing manual build commands written to %s\n", BuildReportFile)
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+	},
+}
+
+func (repo *Repository) diagnosePullRequest(client Client, pullRequest PullRequest) ([]Diagnosis, error) {
+	type WorkflowRuns struct {
+		WorkflowRuns []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Path       string `json:"path"`
+			Conclusion string `json:"conclusion"`
+		} `json:"workflow_runs"`
+	}
+
+	type Jobs struct {
+		Jobs []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			HTMLURL    string `json:"html_url"`
+			Conclusion string `json:"conclusion"`
+			RunnerName string `json:"runner_name"`
+			Steps      []struct {
+				Name string `json:"name"`
+			} `json:"steps"`
+		} `json:"jobs"`
+	}
+
+	var runs WorkflowRuns
+	requestPath := fmt.Sprintf("repos/%s/actions/runs?branch=%s&head_sha=%s&per_page=100", repo.FullName, pullRequest.Head.Ref, pullRequest.Head.Sha)
+	_, _, err := callApi(client, requestPath, &runs, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get workflow runs for pull request #%d in repository %s\n", pullRequest.Number, repo.FullName)
+		return nil, err
+	}
+
+	var diagnoses []Diagnosis
+	for _, run := range runs.WorkflowRuns {
+		// other workflows on the branch are not part of the rollout
+		if run.Conclusion != "failure" || !isCodeqlRun(run.Name, run.Path) {
+			continue
+		}
+
+		var jobs Jobs
+		requestPath = fmt.Sprintf("repos/%s/actions/runs/%d/jobs?per_page=100", repo.FullName, run.ID)
+		_, _, err := callApi(client, requestPath, &jobs, GET)
+		if err != nil {
+			log.Printf("ERROR: Unable to get jobs for workflow run %d in repository %s\n", run.ID, repo.FullName)
+			return diagnoses, err
+		}
+
+		for _, job := range jobs.Jobs {
+			// cancelled, timed out and skipped jobs did not fail on their own
+			if job.Conclusion != "failure" || !isCodeqlAnalyzeJob(job.Name) {
+				continue
+			}
+
+			diagnosis := Diagnosis{
+				Repository:  repo.FullName,
+				PullRequest: pullRequest.HTMLURL,
+				Job:         job.Name,
+				JobURL:      job.HTMLURL,
+				Signature:   "unknown",
+			}
+
+			var signature *FailureSignature
+			if len(job.RunnerName) <= 0 && len(job.Steps) <= 0 {
+				// the job failed without ever getting a runner, so there are no logs to look at
+				signature = findFailureSignature("unsupported-runner")
+			} else {
+				_, jobLog, err := callApiRaw(client, fmt.Sprintf("repos/%s/actions/jobs/%d/logs", repo.FullName, job.ID))
+				if err != nil {
+					log.Printf("ERROR: Unable to get logs for job %s in repository %s\n", job.Name, repo.FullName)
+					return diagnoses, err
+				}
+				signature = matchFailureSignature(string(jobLog))
+			}
+
+			if signature != nil {
+				diagnosis.Signature = signature.Name
+				diagnosis.Suggestion = signature.Suggestion
+				diagnosis.ManualBuild = signature.ManualBuild
+			}
+			log.Printf("Job \"%s\" failed for repository %s: %s\n", job.Name, repo.FullName, diagnosis.Signature)
+			diagnoses = append(diagnoses, diagnosis)
+		}
+	}
+
+	return diagnoses, nil
+}
+
+// isCodeqlRun reports whether the workflow run is the CodeQL workflow the rollout added.
+func isCodeqlRun(name string, path string) bool {
+	return strings.HasPrefix(path, ".github/workflows/codeql.yml") || strings.Contains(strings.ToLower(name), "codeql")
+}
+
+// isCodeqlAnalyzeJob reports whether the job is a CodeQL analysis job, including those of a called reusable workflow e.g. "codeql / Analyze (go)".
+func isCodeqlAnalyzeJob(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "analyze") || strings.Contains(name, "codeql")
+}
+
+func matchFailureSignature(jobLog string) *FailureSignature {
+	for i := range failureSignatures {
+		if failureSignatures[i].Pattern.MatchString(jobLog) {
+			return &failureSignatures[i]
+		}
+	}
+	return nil
+}
+
+func findFailureSignature(name string) *FailureSignature {
+	for i := range failureSignatures {
+		if failureSignatures[i].Name == name {
+			return &failureSignatures[i]
+		}
+	}
+	return nil
+}
+
+func (repo *Repository) commentDiagnosis(client Client, number int, diagnosis Diagnosis) (string, error) {
+	// the marker makes sure the same failure is only commented on once
+	marker := fmt.Sprintf("<!-- gh-add-files:diagnose:%s -->", diagnosis.Signature)
+
+	comments, err := repo.listComments(client, number)
+	if err != nil {
+		return "", err
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			log.Printf("Pull request #%d in repository %s already has a comment for %s, skipping\n", number, repo.FullName, diagnosis.Signature)
+			return "", nil
+		}
+	}
+
+	title := diagnosis.Signature
+	if signature := findFailureSignature(diagnosis.Signature); signature != nil {
+		title = signature.Title
+	}
+
+	body := fmt.Sprintf("%s\n## CodeQL check failed: %s\n\nThe job [%s](%s) failed.\n\n%s\n\nIf you require any further assistance, please contact the security team.\n", marker, title, diagnosis.Job, diagnosis.JobURL, diagnosis.Suggestion)
+	return repo.commentOnPullRequest(client, number, body)
+}
+
+func writeRepositoryCsv(path string, repositories []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("ERROR: Unable to create csv file %s\n", path)
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	for _, repository := range repositories {
+		if err := w.Write([]string{repository}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
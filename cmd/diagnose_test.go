@@ -0,0 +1,110 @@
+package cmd
+
+import "testing"
+
+func Test_matchFailureSignature(t *testing.T) {
+	tests := []struct {
+		name   string
+		jobLog string
+		want   string
+	}{
+		{
+			name:   "When autobuild could not build the code",
+			jobLog: "2024-01-01T00:00:00Z ##[error]We were unable to automatically build your code. Please replace the call to the autobuild action with your custom build steps.",
+			want:   "autobuild-failed",
+		},
+		{
+			name:   "When no source code was seen",
+			jobLog: "CodeQL detected code written in Java/Kotlin but could not process any of it.",
+			want:   "no-source-code",
+		},
+		{
+			name:   "When the analysis ran out of memory",
+			jobLog: "Exception in thread \"main\" java.lang.OutOfMemoryError: Java heap space",
+			want:   "out-of-memory",
+		},
+		{
+			name:   "When the runner is not available",
+			jobLog: "No runner matching the specified labels was found: macos-10.15",
+			want:   "unsupported-runner",
+		},
+		{
+			name:   "When the failure is unknown",
+			jobLog: "##[error]Process completed with exit code 1.",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchFailureSignature(tt.jobLog)
+			if got == nil {
+				if tt.want != "" {
+					t.Errorf("matchFailureSignature() = nil, want %v", tt.want)
+				}
+				return
+			}
+			if got.Name != tt.want {
+				t.Errorf("matchFailureSignature() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_diagnosePullRequest(t *testing.T) {
+	pullRequest := PullRequest{Number: 7, HTMLURL: "https://github.com/paradisisland/titanforest/pull/7"}
+	pullRequest.Head.Ref = "gh-cli/codescanningworkflow"
+	pullRequest.Head.Sha = "aa218f56b14c9653891f9e74264a383fa43fefbd"
+
+	tests := []struct {
+		name     string
+		fullName string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "When only CodeQL analyze jobs that failed are diagnosed",
+			fullName: "paradisisland/titanforest",
+			want:     map[string]string{"Analyze (java-kotlin)": "autobuild-failed", "Analyze (swift)": "unsupported-runner"},
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.diagnosePullRequest(&TestClient{}, pullRequest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.diagnosePullRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Repository.diagnosePullRequest() = %v, want %v", got, tt.want)
+				return
+			}
+			for _, diagnosis := range got {
+				if diagnosis.Signature != tt.want[diagnosis.Job] {
+					t.Errorf("Repository.diagnosePullRequest() job %s = %v, want %v", diagnosis.Job, diagnosis.Signature, tt.want[diagnosis.Job])
+				}
+			}
+		})
+	}
+}
+
+func Test_isCodeqlRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		runName string
+		path    string
+		want    bool
+	}{
+		{name: "When the run is the rollout workflow", runName: "Code Scanning", path: ".github/workflows/codeql.yml", want: true},
+		{name: "When the run is named after CodeQL", runName: "CodeQL Advanced", path: ".github/workflows/security.yml", want: true},
+		{name: "When the run is another workflow", runName: "CI", path: ".github/workflows/ci.yml", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCodeqlRun(tt.runName, tt.path); got != tt.want {
+				t.Errorf("isCodeqlRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
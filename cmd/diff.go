@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+var DiffInPullRequest bool
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// maxPullRequestDiff is how much of a diff is added to a PR body, which GitHub limits to 65536 characters.
+const maxPullRequestDiff = 60000
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines compares the lines with their longest common subsequence and marks each line as kept ' ', removed '-' or added '+'.
+func diffLines(from []string, to []string) []diffLine {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			lines = append(lines, diffLine{' ', from[i]})
+			i++
+			j++
+		case j >= len(to) || (i < len(from) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', from[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', to[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if len(text) <= 0 {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// unifiedDiff returns the changes from one file to the other in the unified format, or an empty string when the lines are the same.
+func unifiedDiff(fromName string, toName string, from []byte, to []byte) string {
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	var changes []int
+	for i, line := range lines {
+		if line.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) <= 0 {
+		return ""
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", fromName, toName)
+
+	for first := 0; first < len(changes); {
+		// changes closer than twice the context share a hunk
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContext {
+			last++
+		}
+		start := max(changes[first]-diffContext, 0)
+		end := min(changes[last]+diffContext+1, len(lines))
+
+		fromStart, toStart := 0, 0
+		for _, line := range lines[:start] {
+			if line.op != '+' {
+				fromStart++
+			}
+			if line.op != '-' {
+				toStart++
+			}
+		}
+		fromCount, toCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				fromCount++
+			}
+			if line.op != '-' {
+				toCount++
+			}
+		}
+
+		fmt.Fprintf(&diff, "@@ -%s +%s @@\n", hunkRange(fromStart, fromCount), hunkRange(toStart, toCount))
+		for _, line := range lines[start:end] {
+			fmt.Fprintf(&diff, "%c%s\n", line.op, line.text)
+		}
+		first = last + 1
+	}
+	return diff.String()
+}
+
+// hunkRange formats the start and length of a hunk, where an empty range starts at the line before it.
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	} else if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// pullRequestDiff formats the diff as a section of a PR body.
+func pullRequestDiff(diff string) string {
+	if len(diff) <= 0 {
+		return ""
+	}
+	note := ""
+	if len(diff) > maxPullRequestDiff {
+		diff = diff[:strings.LastIndex(diff[:maxPullRequestDiff], "\n")+1]
+		note = "\nThe diff is too long to show in full, please see the files changed tab.\n"
+	}
+	return fmt.Sprintf("\n## What changes?\n\n```diff\n%s```\n%s", diff, note)
+}
+
+// diffCodeqlWorkflowFile compares the new workflow with the codeql.yml on the default branch.
+// With the merge flag the new workflow is merged into the existing one first, and the workflow to commit is returned with the diff.
+func (repo *Repository) diffCodeqlWorkflowFile(client Client, workflowFile []byte) ([]byte, string, error) {
+	existing, _, err := repo.getFileContent(client, ".github/workflows/codeql.yml", repo.DefaultBranch)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing == nil {
+		return workflowFile, unifiedDiff("/dev/null", "b/.github/workflows/codeql.yml", nil, workflowFile), nil
+	}
+
+	if MergeWorkflow {
+		workflowFile, err = mergeWorkflow(existing, workflowFile)
+		if err != nil {
+			log.Printf("ERROR: Unable to merge the CodeQL workflow file for repository: %s\n", repo.FullName)
+			return nil, "", err
+		}
+	}
+	return workflowFile, unifiedDiff("a/.github/workflows/codeql.yml", "b/.github/workflows/codeql.yml", existing, workflowFile), nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "When the files are the same",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: "",
+		},
+		{
+			name: "When a line is changed",
+			from: "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v3\n",
+			to:   "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n",
+			want: `--- a/codeql.yml
++++ b/codeql.yml
+@@ -3,4 +3,4 @@
+   analyze:
+     runs-on: ubuntu-latest
+     steps:
+-      - uses: actions/checkout@v3
++      - uses: actions/checkout@v4
+`,
+		},
+		{
+			name: "When changes are far apart",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			want: `--- a/codeql.yml
++++ b/codeql.yml
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -7,4 +8,3 @@
+ 7
+ 8
+ 9
+-10
+`,
+		},
+		{
+			name: "When the file is new",
+			from: "",
+			to:   "a\nb\n",
+			want: `--- a/codeql.yml
++++ b/codeql.yml
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a/codeql.yml", "b/codeql.yml", []byte(tt.from), []byte(tt.to)); got != tt.want {
+				t.Errorf("unifiedDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pullRequestDiff(t *testing.T) {
+	if got := pullRequestDiff(""); got != "" {
+		t.Errorf("pullRequestDiff() = %v, want an empty string", got)
+	}
+
+	got := pullRequestDiff(strings.Repeat("+a\n", maxPullRequestDiff))
+	if len(got) > 65536 || !strings.Contains(got, "too long to show in full") {
+		t.Errorf("pullRequestDiff() length = %d, want a truncated diff", len(got))
+	}
+}
+
+func TestRepository_diffCodeqlWorkflowFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		merge    bool
+		want     string
+		wantDiff string
+		wantErr  bool
+	}{
+		{
+			name:     "When the existing workflow is merged",
+			fullName: "paradisisland/shiganshima",
+			merge:    true,
+			want: `name: CodeQL
+on:
+  push:
+    branches: [ "main" ]
+  pull_request:
+    branches: [ "main" ]
+  schedule:
+    - cron: '30 1 * * 0'
+jobs:
+  analyze:
+    runs-on: [ self-hosted ]
+    strategy:
+      matrix:
+        language: [ 'go', 'python' ]
+    steps:
+      - uses: actions/checkout@v4
+      - run: make build
+`,
+			wantDiff: `--- a/.github/workflows/codeql.yml
++++ b/.github/workflows/codeql.yml
+@@ -1,13 +1,17 @@
+ name: CodeQL
+ on:
+   push:
+-    branches: [ main ]
++    branches: [ "main" ]
++  pull_request:
++    branches: [ "main" ]
++  schedule:
++    - cron: '30 1 * * 0'
+ jobs:
+   analyze:
+     runs-on: [ self-hosted ]
+     strategy:
+       matrix:
+-        language: [ go ]
++        language: [ 'go', 'python' ]
+     steps:
+-      - uses: actions/checkout@v3
++      - uses: actions/checkout@v4
+       - run: make build
+`,
+			wantErr: false,
+		},
+		{
+			name:     "When the repository has no CodeQL workflow",
+			fullName: "paradisisland/maria",
+			merge:    false,
+			want:     renderedWorkflow,
+			wantDiff: "--- /dev/null\n+++ b/.github/workflows/codeql.yml\n@@ -0,0 +1,27 @@\n",
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			MergeWorkflow = tt.merge
+			defer func() { MergeWorkflow = false }()
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, diff, err := repo.diffCodeqlWorkflowFile(client, []byte(renderedWorkflow))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.diffCodeqlWorkflowFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Repository.diffCodeqlWorkflowFile() = %v, want %v", string(got), tt.want)
+			}
+			if !strings.HasPrefix(diff, tt.wantDiff) {
+				t.Errorf("Repository.diffCodeqlWorkflowFile() diff = %v, want %v", diff, tt.wantDiff)
+			}
+		})
+	}
+}
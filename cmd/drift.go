@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var UpdateDrifted bool
+
+const (
+	DriftIdentical  = "identical"
+	DriftDrifted    = "drifted"
+	DriftMissing    = "missing"
+	DriftCustomized = "customized"
+)
+
+// DriftStatus compares the codeql.yml and CodeQL config file of a repository with the files code-scanning would commit to it.
+type DriftStatus struct {
+	Repository  string `json:"repository"`
+	Status      string `json:"status"`
+	Config      string `json:"config,omitempty"`
+	Diff        string `json:"diff,omitempty"`
+	PullRequest string `json:"pull_request,omitempty"`
+	// workflowFile and configFile are the files an update commits, or nil when they do not need one
+	workflowFile []byte
+	configFile   []byte
+	sha          string
+}
+
+func init() {
+	driftCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to check the workflow files of")
+	driftCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	driftCmd.MarkFlagsMutuallyExclusive("csv", "organization")
+	driftCmd.PersistentFlags().StringVarP(&WorkflowFile, "workflow", "w", "", "specify the path to the code scanning workflow file")
+	driftCmd.PersistentFlags().StringVarP(&TemplateFile, "template", "t", "", "specify the path to the code scanning workflow template file")
+	driftCmd.MarkFlagsMutuallyExclusive("workflow", "template")
+	driftCmd.PersistentFlags().StringVar(&ConfigFile, "config-file", "", "specify the path to the CodeQL config file template to compare with .github/codeql/codeql-config.yml")
+	driftCmd.PersistentFlags().StringVar(&BuildConfigFile, "build-config", "", "specify the path to the YAML file of manual build commands that code-scanning used")
+	driftCmd.PersistentFlags().BoolVar(&PinActions, "pin-actions", false, "pin the actions and reusable workflows of other owners in the workflow file, as code-scanning did")
+	driftCmd.PersistentFlags().BoolVar(&SkipReferenceCheck, "skip-reference-check", false, "do not check that the actions and reusable workflows the workflow file uses exist and are accessible from each repository")
+	driftCmd.PersistentFlags().BoolVar(&UpdateDrifted, "update", false, "open a PR that updates the workflow file of each drifted repository")
+	driftCmd.PersistentFlags().StringVarP(&OutputFormat, "format", "F", "table", "specify the output format: table or json")
+	driftCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+	driftCmd.PersistentFlags().BoolVarP(&Force, "force", "f", false, "replace the workflow branch if it already exists when opening update PRs")
+}
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect workflow files that drifted from the template",
+	Long:  "Compare the codeql.yml on the default branch of each repo in organisation, csv file or argument list with the workflow the template renders, and optionally open PRs that update the drifted ones",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		// logs go to stderr so the table or JSON output can be piped
+		logFile, err := setupLogging(LogFile, os.Stderr)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		// check if organization or csv file is provided
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		if len(WorkflowFile) <= 0 && len(TemplateFile) <= 0 {
+			log.Fatalln("ERROR: Either workflow flag or template flag must be provided")
+		}
+
+		if OutputFormat != "table" && OutputFormat != "json" {
+			log.Fatalf("ERROR: Unknown output format %s, must be table or json\n", OutputFormat)
+		}
+
+		if err := loadBuildCommands(BuildConfigFile, CsvFile); err != nil {
+			log.Fatalln(err)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var statuses []DriftStatus
+		for _, repo := range repos {
+			status, err := repo.checkDrift(client)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			if len(status.Diff) > 0 && OutputFormat == "table" {
+				config := ""
+				if len(status.Config) > 0 {
+					config = fmt.Sprintf(", the config file is %s", status.Config)
+				}
+				log.Printf("The workflow file of repository %s is %s%s:\n%s", repo.FullName, status.Status, config, status.Diff)
+			}
+
+			if UpdateDrifted && (status.workflowFile != nil || status.configFile != nil) {
+				createdPR, err := repo.raiseDriftPullRequest(client, status)
+				if err != nil {
+					Errors[repo.FullName] = err
+				}
+				status.PullRequest = createdPR
+			}
+			statuses = append(statuses, status)
+		}
+
+		if OutputFormat == "json" {
+			err = printJSON(os.Stdout, statuses)
+		} else {
+			var rows [][]string
+			for _, status := range statuses {
+				rows = append(rows, []string{status.Repository, status.Status, status.Config, status.PullRequest})
+			}
+			err = printTable(os.Stdout, []string{"REPOSITORY", "STATUS", "CONFIG", "PULL REQUEST"}, rows)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+	},
+}
+
+// classifyDrift compares the existing workflow with the rendered one.
+// A workflow that only differs in the keys add-files manages has drifted, and one that differs anywhere else was customized in the repository.
+func classifyDrift(existing []byte, rendered []byte) string {
+	if existing == nil {
+		return DriftMissing
+	}
+	diff := unifiedDiff("a", "b", existing, rendered)
+	if len(diff) <= 0 {
+		return DriftIdentical
+	}
+
+	merged, err := mergeWorkflow(existing, rendered)
+	if err != nil || len(unifiedDiff("a", "b", merged, rendered)) > 0 {
+		return DriftCustomized
+	}
+	return DriftDrifted
+}
+
+// renderExpectedWorkflow renders the workflow code-scanning would commit to the repository, with its build steps and pinned actions.
+// The actions are also pinned when the existing workflow pins them, so an update never unpins them.
+func (repo *Repository) renderExpectedWorkflow(client Client, existing []byte, languages []string) ([]byte, error) {
+	rendered, err := repo.renderCodeqlWorkflowFile()
+	if err != nil {
+		return nil, err
+	}
+
+	workflowFile, _, err := repo.prepareCodeqlWorkflowFile(client, rendered, languages, false)
+	if err != nil {
+		return nil, err
+	}
+	if !PinActions && hasPinnedActions(existing) {
+		return repo.pinWorkflowActions(client, workflowFile)
+	}
+	return workflowFile, nil
+}
+
+// checkDrift classifies the codeql.yml on the default branch and, when a config file template is set, the CodeQL config file.
+// The status keeps the files an update has to commit.
+func (repo *Repository) checkDrift(client Client) (DriftStatus, error) {
+	status := DriftStatus{Repository: repo.FullName}
+	existing, sha, err := repo.getFileContent(client, ".github/workflows/codeql.yml", repo.DefaultBranch)
+	if err != nil {
+		return status, err
+	}
+	if existing == nil {
+		status.Status = DriftMissing
+		return status, nil
+	}
+
+	languages, err := repo.GetCodeqlLanguages(client)
+	if err != nil {
+		return status, err
+	}
+	workflowFile, err := repo.renderExpectedWorkflow(client, existing, languages)
+	if err != nil {
+		return status, err
+	}
+
+	status.Status = classifyDrift(existing, workflowFile)
+	if status.Status == DriftDrifted || status.Status == DriftCustomized {
+		status.Diff = unifiedDiff("a/.github/workflows/codeql.yml", "b/.github/workflows/codeql.yml", existing, workflowFile)
+	}
+	if status.Status == DriftDrifted {
+		status.workflowFile, status.sha = workflowFile, sha
+	}
+
+	// the config file is managed as a whole, so any difference is drift
+	if len(ConfigFile) > 0 {
+		configFile, diff, err := repo.prepareCodeqlConfigFile(client, languages)
+		if err != nil {
+			return status, err
+		}
+		if len(diff) <= 0 {
+			status.Config = DriftIdentical
+		} else {
+			status.Config = DriftDrifted
+			if strings.HasPrefix(diff, "--- /dev/null") {
+				status.Config = DriftMissing
+			}
+			status.configFile = configFile
+			status.Diff += diff
+		}
+	}
+	return status, nil
+}
+
+// raiseDriftPullRequest opens a PR that replaces the drifted workflow file and config file with the expected ones.
+func (repo *Repository) raiseDriftPullRequest(client Client, status DriftStatus) (string, error) {
+	if status.workflowFile != nil {
+		if err := repo.validateCodeqlWorkflowFile(status.workflowFile); err != nil {
+			return "", err
+		}
+	}
+
+	newbranchref, err := repo.createOrReplaceBranch(client, workflowBranch)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Ref created succesfully at : %s\n", newbranchref)
+
+	if status.workflowFile != nil {
+		if _, err := repo.commitWorkflowFile(client, status.workflowFile, status.sha); err != nil {
+			return "", err
+		}
+	}
+	if status.configFile != nil {
+		if err := repo.commitCodeqlConfigFile(client, status.configFile); err != nil {
+			return "", err
+		}
+	}
+
+	createdPR, err := repo.openPullRequest(client, workflowBranch, "Automated PR: CodeQL workflow updated", driftPullRequestBody+pullRequestDiff(status.Diff))
+	if err != nil {
+		return "", err
+	}
+	if len(createdPR) <= 0 {
+		return "", errors.New("Something went wrong when creating new pull request")
+	}
+	log.Printf("Successfully raised pull request %s on branch %s in repository %s\n", createdPR, newbranchref, repo.FullName)
+	return createdPR, nil
+}
+
+const driftPullRequestBody = `
+## What does this PR do?
+
+This is an automated PR created by your security team to bring the CodeQL workflow of your repository up to date with the latest version of the workflow template. Only the triggers, action versions and languages of the workflow, and the CodeQL config file, which are managed by the security team, have changed.
+
+## How do I merge this PR?
+
+This PR should have triggered CodeQL scans for each language in this repository. If these jobs have passed, you can merge this PR. If they have failed, please take a look at the logs and contact the security team if you require assistance.
+
+If you require any further assistance, please contact the security team.
+`
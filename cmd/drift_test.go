@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// driftedWorkflow is the workflow of paradisisland/shiganshima with the managed keys of renderedWorkflow.
+const driftedWorkflow = `name: CodeQL
+on:
+  push:
+    branches: [ "main" ]
+  pull_request:
+    branches: [ "main" ]
+jobs:
+  analyze:
+    runs-on: [ self-hosted ]
+    strategy:
+      matrix:
+        language: [ 'go', 'python' ]
+    steps:
+      - uses: actions/checkout@v4
+      - run: make build
+`
+
+func Test_classifyDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []byte
+		rendered string
+		want     string
+	}{
+		{
+			name:     "When the repository has no workflow file",
+			existing: nil,
+			rendered: renderedWorkflow,
+			want:     DriftMissing,
+		},
+		{
+			name:     "When the workflow file matches the template",
+			existing: []byte(renderedWorkflow),
+			rendered: renderedWorkflow,
+			want:     DriftIdentical,
+		},
+		{
+			name:     "When only the managed keys differ",
+			existing: []byte("on: [ push ]\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@v3\n"),
+			rendered: "on: [ push, pull_request ]\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@v4\n",
+			want:     DriftDrifted,
+		},
+		{
+			name:     "When the workflow file has local changes",
+			existing: []byte("on: [ push ]\njobs:\n  analyze:\n    runs-on: [ self-hosted ]\n"),
+			rendered: "on: [ push ]\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n",
+			want:     DriftCustomized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyDrift(tt.existing, []byte(tt.rendered)); got != tt.want {
+				t.Errorf("classifyDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_renderExpectedWorkflow(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "codeql.yml")
+	if err := os.WriteFile(template, []byte("on: [ push ]\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name:     "When the existing workflow uses actions by tag",
+			existing: "on: [ push ]\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@v3\n",
+			want:     "on: [ push ]\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n",
+		},
+		{
+			name:     "When the existing workflow pins its actions they stay pinned",
+			existing: "on: [ push ]\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v3\n",
+			want:     "on: [ push ]\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TemplateFile, SkipReferenceCheck = template, true
+			defer func() { TemplateFile, SkipReferenceCheck = "", false }()
+			repo := &Repository{FullName: "paradisisland/shiganshima", DefaultBranch: "main"}
+			got, err := repo.renderExpectedWorkflow(&TestClient{}, []byte(tt.existing), []string{"C"})
+			if err != nil {
+				t.Fatalf("Repository.renderExpectedWorkflow() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Repository.renderExpectedWorkflow() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_checkDrift(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	driftedTemplate := writeFile("drifted.yml", driftedWorkflow)
+	renderedTemplate := writeFile("rendered.yml", renderedWorkflow)
+	invalidTemplate := writeFile("invalid.yml", "name: CodeQL\non: push\n")
+	identicalConfig := writeFile("identical-config.yml", "name: CodeQL config\n")
+	driftedConfig := writeFile("drifted-config.yml", "name: CodeQL config\nqueries:\n  - uses: security-extended\n")
+
+	tests := []struct {
+		name         string
+		fullName     string
+		template     string
+		configFile   string
+		want         string
+		wantConfig   string
+		wantWorkflow bool
+		wantErr      bool
+	}{
+		{
+			name:         "When the workflow file only differs in the managed keys",
+			fullName:     "paradisisland/shiganshima",
+			template:     driftedTemplate,
+			want:         DriftDrifted,
+			wantWorkflow: true,
+			wantErr:      false,
+		},
+		{
+			name:         "When the workflow file was customized",
+			fullName:     "paradisisland/shiganshima",
+			template:     renderedTemplate,
+			want:         DriftCustomized,
+			wantWorkflow: false,
+			wantErr:      false,
+		},
+		{
+			name:         "When the repository has no workflow file",
+			fullName:     "paradisisland/maria",
+			template:     renderedTemplate,
+			want:         DriftMissing,
+			wantWorkflow: false,
+			wantErr:      false,
+		},
+		{
+			name:         "When the config file matches the template",
+			fullName:     "paradisisland/shiganshima",
+			template:     driftedTemplate,
+			configFile:   identicalConfig,
+			want:         DriftDrifted,
+			wantConfig:   DriftIdentical,
+			wantWorkflow: true,
+			wantErr:      false,
+		},
+		{
+			name:         "When the config file drifted from the template",
+			fullName:     "paradisisland/shiganshima",
+			template:     renderedTemplate,
+			configFile:   driftedConfig,
+			want:         DriftCustomized,
+			wantConfig:   DriftDrifted,
+			wantWorkflow: false,
+			wantErr:      false,
+		},
+		{
+			name:     "When the template renders an invalid workflow",
+			fullName: "paradisisland/shiganshima",
+			template: invalidTemplate,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TemplateFile, ConfigFile, SkipReferenceCheck = tt.template, tt.configFile, true
+			defer func() { TemplateFile, ConfigFile, SkipReferenceCheck = "", "", false }()
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.checkDrift(&TestClient{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.checkDrift() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Status != tt.want || got.Config != tt.wantConfig {
+				t.Errorf("Repository.checkDrift() = %v, %v, want %v, %v", got.Status, got.Config, tt.want, tt.wantConfig)
+			}
+			if (got.workflowFile != nil) != tt.wantWorkflow {
+				t.Errorf("Repository.checkDrift() workflow file = %v, want one %v", string(got.workflowFile), tt.wantWorkflow)
+			}
+			if (got.configFile != nil) != (tt.wantConfig == DriftDrifted || tt.wantConfig == DriftMissing) {
+				t.Errorf("Repository.checkDrift() config file = %v", string(got.configFile))
+			}
+			if (got.Status == DriftMissing) != (len(got.Diff) <= 0) {
+				t.Errorf("Repository.checkDrift() diff = %v", got.Diff)
+			}
+		})
+	}
+}
+
+func TestRepository_raiseDriftPullRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  DriftStatus
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "When the workflow file drifted",
+			status:  DriftStatus{Status: DriftDrifted, workflowFile: []byte(driftedWorkflow), sha: "3d21ec53a331a6f037a91c368710b99387d012c1"},
+			want:    "https://github.com/paradisisland/shiganshima/pull/1348",
+			wantErr: false,
+		},
+		{
+			name:    "When only the config file drifted",
+			status:  DriftStatus{Status: DriftCustomized, Config: DriftDrifted, configFile: []byte("name: CodeQL config\n")},
+			want:    "https://github.com/paradisisland/shiganshima/pull/1348",
+			wantErr: false,
+		},
+		{
+			name:    "When the workflow file is invalid",
+			status:  DriftStatus{Status: DriftDrifted, workflowFile: []byte("name: CodeQL\non: push\n")},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: "paradisisland/shiganshima", DefaultBranch: "main"}
+			got, err := repo.raiseDriftPullRequest(&TestClient{}, tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.raiseDriftPullRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.raiseDriftPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+var EnableGhas bool
+var SecretScanning bool
+var PushProtection bool
+var DependabotSecurityUpdates bool
+
+// SecurityFeaturesEnabled holds the repositories where Advanced Security was enabled and the committer seat impact.
+var SecurityFeaturesEnabled = make(map[string]string)
+
+// AdvancedSecurityBilling holds the Advanced Security committers of an organization.
+type AdvancedSecurityBilling struct {
+	TotalCommitters     int `json:"total_advanced_security_committers"`
+	MaximumCommitters   int `json:"maximum_advanced_security_committers"`
+	PurchasedCommitters int `json:"purchased_advanced_security_committers"`
+	Repositories        []struct {
+		Name      string `json:"name"`
+		Breakdown []struct {
+			UserLogin string `json:"user_login"`
+		} `json:"advanced_security_committers_breakdown"`
+	} `json:"repositories"`
+	// committers are the logins that already use a seat
+	committers map[string]bool
+}
+
+// advancedSecurityBilling caches the committers of each organization, so they are read once per run and the repositories enabled during the run add to them.
+var advancedSecurityBilling = map[string]*AdvancedSecurityBilling{}
+
+// committerWindow is how far back a push makes a committer active and use an Advanced Security seat.
+const committerWindow = 90 * 24 * time.Hour
+
+// securityAndAnalysis builds the security_and_analysis settings that enable Advanced Security and the configured features.
+func securityAndAnalysis() map[string]map[string]string {
+	settings := scanningFeatures()
+	settings["advanced_security"] = map[string]string{"status": "enabled"}
+	return settings
+}
+
+// scanningFeatures builds the security_and_analysis settings for the configured secret scanning features only.
+func scanningFeatures() map[string]map[string]string {
+	enabled := map[string]string{"status": "enabled"}
+	settings := map[string]map[string]string{}
+	if SecretScanning || PushProtection {
+		settings["secret_scanning"] = enabled
+	}
+	if PushProtection {
+		settings["secret_scanning_push_protection"] = enabled
+	}
+	return settings
+}
+
+// ensureAdvancedSecurity enables Advanced Security and the configured features when the repository does not have Advanced Security enabled.
+// When it is already enabled, only the configured features are applied.
+// It returns whether Advanced Security was enabled and the estimated committer seat impact.
+func (repo *Repository) ensureAdvancedSecurity(client Client) (bool, string, error) {
+	// default setup can only be read when Advanced Security is enabled
+	_, statusCode, _ := repo.getDefaultSetupState(client)
+	if statusCode != 403 {
+		return false, "", repo.applySecurityFeatures(client)
+	}
+
+	log.Printf("Enabling Advanced Security for repository %s\n", repo.FullName)
+	impact, err := repo.enableSecurityFeatures(client)
+	if err != nil {
+		return false, "", err
+	}
+	return true, impact, nil
+}
+
+// enableSecurityFeatures enables Advanced Security and the configured scanning features for the repository.
+// It returns an estimate of the committer seat impact, or an empty string when the billing API is not available.
+func (repo *Repository) enableSecurityFeatures(client Client) (string, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{"security_and_analysis": securityAndAnalysis()})
+	if err != nil {
+		log.Printf("ERROR: Unable to marshal JSON request body\n")
+		return "", err
+	}
+
+	requestPath := fmt.Sprintf("repos/%s", repo.FullName)
+	statusCode, _, err := callApi(client, requestPath, nil, PATCH, jsonData)
+	if statusCode == 403 || statusCode == 422 {
+		log.Printf("ERROR: Advanced Security cannot be enabled for repository %s, check the licences and the organization settings\n", repo.FullName)
+		return "", err
+	} else if statusCode != 200 {
+		log.Printf("ERROR: Unable to enable Advanced Security for repository %s\n", repo.FullName)
+		return "", err
+	}
+	log.Printf("Successfully enabled Advanced Security for repository %s\n", repo.FullName)
+
+	if DependabotSecurityUpdates {
+		if err := repo.enableDependabotSecurityUpdates(client); err != nil {
+			return "", err
+		}
+	}
+
+	return repo.estimateSeatImpact(client), nil
+}
+
+// applySecurityFeatures enables the configured scanning features for a repository that already has Advanced Security enabled.
+func (repo *Repository) applySecurityFeatures(client Client) error {
+	if settings := scanningFeatures(); len(settings) > 0 {
+		jsonData, err := json.Marshal(map[string]interface{}{"security_and_analysis": settings})
+		if err != nil {
+			log.Printf("ERROR: Unable to marshal JSON request body\n")
+			return err
+		}
+
+		requestPath := fmt.Sprintf("repos/%s", repo.FullName)
+		statusCode, _, err := callApi(client, requestPath, nil, PATCH, jsonData)
+		if statusCode != 200 {
+			log.Printf("ERROR: Unable to enable secret scanning for repository %s\n", repo.FullName)
+			return err
+		}
+		log.Printf("Successfully enabled secret scanning for repository %s\n", repo.FullName)
+	}
+
+	if DependabotSecurityUpdates {
+		return repo.enableDependabotSecurityUpdates(client)
+	}
+	return nil
+}
+
+// estimateSeatImpact counts the active committers of the repository that do not use an Advanced Security seat of the organization yet.
+// GitHub recomputes the billing some time after Advanced Security is enabled, so the impact is estimated from the committers instead.
+func (repo *Repository) estimateSeatImpact(client Client) string {
+	billing := repo.getAdvancedSecurityBilling(client)
+	if billing == nil {
+		return ""
+	}
+
+	committers, err := repo.getActiveCommitters(client)
+	if err != nil {
+		log.Printf("WARN: Unable to get the active committers of repository %s, the committer seat impact will not be reported\n", repo.FullName)
+		return ""
+	}
+
+	newCommitters := 0
+	for _, login := range committers {
+		if !billing.committers[login] {
+			billing.committers[login] = true
+			newCommitters++
+		}
+	}
+	billing.TotalCommitters += newCommitters
+
+	impact := fmt.Sprintf("%d new committers, %d committers in total", newCommitters, billing.TotalCommitters)
+	if billing.PurchasedCommitters > 0 && billing.TotalCommitters > billing.PurchasedCommitters {
+		log.Printf("WARN: Organization %s will use about %d Advanced Security committers but only %d are purchased\n", repo.owner(), billing.TotalCommitters, billing.PurchasedCommitters)
+		impact = fmt.Sprintf("%s, %d purchased", impact, billing.PurchasedCommitters)
+	}
+	log.Printf("Estimated Advanced Security committer seat impact for repository %s: %s\n", repo.FullName, impact)
+	return impact
+}
+
+// enableDependabotSecurityUpdates turns on vulnerability alerts, which security updates depend on, and then security updates.
+func (repo *Repository) enableDependabotSecurityUpdates(client Client) error {
+	for _, feature := range []string{"vulnerability-alerts", "automated-security-fixes"} {
+		requestPath := fmt.Sprintf("repos/%s/%s", repo.FullName, feature)
+		statusCode, _, err := callApi(client, requestPath, nil, PUT, nil)
+		if statusCode != 204 {
+			log.Printf("ERROR: Unable to enable %s for repository %s\n", feature, repo.FullName)
+			return err
+		}
+	}
+	log.Printf("Successfully enabled Dependabot security updates for repository %s\n", repo.FullName)
+	return nil
+}
+
+// getAdvancedSecurityBilling returns the Advanced Security committers of the repository owner, or nil when they are not available.
+func (repo *Repository) getAdvancedSecurityBilling(client Client) *AdvancedSecurityBilling {
+	owner := repo.owner()
+	if billing, ok := advancedSecurityBilling[owner]; ok {
+		return billing
+	}
+
+	billing := &AdvancedSecurityBilling{committers: map[string]bool{}}
+	requestPath := fmt.Sprintf("orgs/%s/settings/billing/advanced-security?per_page=100", owner)
+	for {
+		var page AdvancedSecurityBilling
+		_, nextPage, err := callApi(client, requestPath, &page, GET)
+		if err != nil {
+			log.Printf("WARN: Unable to get Advanced Security committers for organization %s, the committer seat impact will not be reported\n", owner)
+			advancedSecurityBilling[owner] = nil
+			return nil
+		}
+		billing.TotalCommitters = page.TotalCommitters
+		billing.MaximumCommitters = page.MaximumCommitters
+		billing.PurchasedCommitters = page.PurchasedCommitters
+		for _, repository := range page.Repositories {
+			for _, committer := range repository.Breakdown {
+				billing.committers[committer.UserLogin] = true
+			}
+		}
+
+		var hasNextPage bool
+		if requestPath, hasNextPage = findNextPage(nextPage); !hasNextPage {
+			break
+		}
+	}
+
+	advancedSecurityBilling[owner] = billing
+	return billing
+}
+
+// getActiveCommitters returns the logins of the users who committed to the default branch of the repository within the committer window.
+func (repo *Repository) getActiveCommitters(client Client) ([]string, error) {
+	type Commit struct {
+		Author *Account `json:"author"`
+	}
+
+	since := time.Now().UTC().Add(-committerWindow).Format("2006-01-02")
+	requestPath := fmt.Sprintf("repos/%s/commits?since=%s&per_page=100", repo.FullName, since)
+	seen := map[string]bool{}
+	var committers []string
+	for {
+		var commits []Commit
+		statusCode, nextPage, err := callApi(client, requestPath, &commits, GET)
+		if statusCode == 409 {
+			// the repository is empty
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			// commits of emails that are not linked to an account have no author
+			if commit.Author == nil || seen[commit.Author.Login] {
+				continue
+			}
+			seen[commit.Author.Login] = true
+			committers = append(committers, commit.Author.Login)
+		}
+
+		var hasNextPage bool
+		if requestPath, hasNextPage = findNextPage(nextPage); !hasNextPage {
+			break
+		}
+	}
+	return committers, nil
+}
+
+// getAdvancedSecurityStatus returns whether Advanced Security is enabled or disabled for the repository, or unknown when the status is not reported.
+func (repo *Repository) getAdvancedSecurityStatus(client Client) (string, error) {
+	type RepositorySettings struct {
+		SecurityAndAnalysis struct {
+			AdvancedSecurity struct {
+				Status string `json:"status"`
+			} `json:"advanced_security"`
+		} `json:"security_and_analysis"`
+	}
+
+	var settings RepositorySettings
+	requestPath := fmt.Sprintf("repos/%s", repo.FullName)
+	_, _, err := callApi(client, requestPath, &settings, GET)
+	if err != nil {
+		log.Printf("ERROR: Unable to get Advanced Security status for repository: %s\n", repo.FullName)
+		return "", err
+	}
+
+	if len(settings.SecurityAndAnalysis.AdvancedSecurity.Status) <= 0 {
+		return "unknown", nil
+	}
+	return settings.SecurityAndAnalysis.AdvancedSecurity.Status, nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_securityAndAnalysis(t *testing.T) {
+	enabled := map[string]string{"status": "enabled"}
+	tests := []struct {
+		name           string
+		secretScanning bool
+		pushProtection bool
+		want           map[string]map[string]string
+	}{
+		{
+			name: "When only Advanced Security is enabled",
+			want: map[string]map[string]string{"advanced_security": enabled},
+		},
+		{
+			name:           "When secret scanning is enabled",
+			secretScanning: true,
+			want:           map[string]map[string]string{"advanced_security": enabled, "secret_scanning": enabled},
+		},
+		{
+			name:           "When push protection is enabled it also enables secret scanning",
+			pushProtection: true,
+			want:           map[string]map[string]string{"advanced_security": enabled, "secret_scanning": enabled, "secret_scanning_push_protection": enabled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SecretScanning, PushProtection = tt.secretScanning, tt.pushProtection
+			defer func() { SecretScanning, PushProtection = false, false }()
+			if got := securityAndAnalysis(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("securityAndAnalysis() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_enableSecurityFeatures(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullName   string
+		dependabot bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "When Advanced Security and Dependabot security updates are enabled",
+			fullName:   "paradisisland/maria",
+			dependabot: true,
+			want:       "2 new committers, 41 committers in total, 40 purchased",
+			wantErr:    false,
+		},
+		{
+			name:     "When there are no Advanced Security licences left",
+			fullName: "paradisisland/rose",
+			want:     "",
+			wantErr:  true,
+		},
+		{
+			name:     "When the committer counts are not available",
+			fullName: "sandora-desert/liberio",
+			want:     "",
+			wantErr:  false,
+		},
+		{
+			name:       "When Dependabot security updates cannot be enabled",
+			fullName:   "sandora-desert/liberio",
+			dependabot: true,
+			want:       "",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			DependabotSecurityUpdates = tt.dependabot
+			defer func() {
+				DependabotSecurityUpdates = false
+				advancedSecurityBilling = map[string]*AdvancedSecurityBilling{}
+			}()
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.enableSecurityFeatures(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.enableSecurityFeatures() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.enableSecurityFeatures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_ensureAdvancedSecurity(t *testing.T) {
+	tests := []struct {
+		name           string
+		fullName       string
+		secretScanning bool
+		dependabot     bool
+		wantEnabled    bool
+		wantErr        bool
+	}{
+		{
+			name:        "When Advanced Security is already enabled",
+			fullName:    "paradisisland/maria",
+			wantEnabled: false,
+			wantErr:     false,
+		},
+		{
+			name:           "When Advanced Security is already enabled the features are still applied",
+			fullName:       "paradisisland/maria",
+			secretScanning: true,
+			dependabot:     true,
+			wantEnabled:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "When the features cannot be applied to a repository that already has Advanced Security",
+			fullName:       "paradisisland/sheena",
+			secretScanning: true,
+			wantEnabled:    false,
+			wantErr:        true,
+		},
+		{
+			name:        "When Advanced Security is not enabled",
+			fullName:    "sandora-desert/liberio",
+			wantEnabled: true,
+			wantErr:     false,
+		},
+		{
+			name:        "When Advanced Security cannot be enabled",
+			fullName:    "paradisisland/rose",
+			wantEnabled: false,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SecretScanning, DependabotSecurityUpdates = tt.secretScanning, tt.dependabot
+			defer func() {
+				SecretScanning, DependabotSecurityUpdates = false, false
+				advancedSecurityBilling = map[string]*AdvancedSecurityBilling{}
+			}()
+			repo := &Repository{FullName: tt.fullName}
+			enabled, _, err := repo.ensureAdvancedSecurity(&TestClient{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.ensureAdvancedSecurity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if enabled != tt.wantEnabled {
+				t.Errorf("Repository.ensureAdvancedSecurity() = %v, want %v", enabled, tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestRepository_estimateSeatImpact(t *testing.T) {
+	defer func() { advancedSecurityBilling = map[string]*AdvancedSecurityBilling{} }()
+	repo := &Repository{FullName: "paradisisland/maria"}
+
+	// the committers of the first estimate use a seat for the second one
+	for _, want := range []string{"2 new committers, 41 committers in total, 40 purchased", "0 new committers, 41 committers in total, 40 purchased"} {
+		if got := repo.estimateSeatImpact(&TestClient{}); got != want {
+			t.Errorf("Repository.estimateSeatImpact() = %v, want %v", got, want)
+		}
+	}
+}
@@ -12,12 +12,22 @@ import (
 // It returns a JSON string, a status code, and an error if the operation fails.
 func MockDeleteResponse(path string) (string, int, error) {
 	switch path {
+	case "repos/paradisisland/sheena/git/refs/heads/gh-cli/codescanningmigration":
+		return `{}`, 204, nil
 	case "repos/paradisisland/maria/git/refs/heads/gh-cli/codescanningworkflow",
 		"repos/paradisisland/shiganshima/git/refs/heads/gh-cli/codescanningworkflow":
 		return `{}`, 204, nil
 	case "repos/paradisisland/rose/git/refs/heads/gh-cli/codescanningworkflow",
 		"repos/paradisisland/marley/git/refs/heads/gh-cli/codescanningworkflow":
 		return `{}`, 422, &api.HTTPError{Message: "Reference does not exist", StatusCode: 422}
+	case "repos/paradisisland/shiganshima/contents/.github/workflows/codeql.yml":
+		return `{"content": null, "commit": {"sha": "7638417db6d59f3c431d3e1f261cc637155684cd"}}`, 200, nil
+	case "repos/paradisisland/maria/contents/.github/workflows/codeql-analysis.yml":
+		return `{"content": null, "commit": {"sha": "4b6d8f0a2c4e6a8b0d2f4e6a8c0b2d4f6e8a0c2b"}}`, 200, nil
+	case "repos/paradisisland/marley/contents/.github/workflows/codeql.yml":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/rose/contents/.github/workflows/codeql.yml":
+		return `{}`, 409, &api.HTTPError{Message: "Conflict", StatusCode: 409}
 	default:
 		return "", 0, fmt.Errorf("MockDeleteResponse: Unhandled path: %s", path)
 	}
@@ -91,6 +101,50 @@ func MockPutResponse(path string) (string, int, error) {
 	case "repos/paradisisland/marley/contents/.github/workflows/codeql.yml":
 		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
 
+	case "repos/paradisisland/maria/contents/.github/workflows/dependency-review.yml":
+		return `{
+			"content": {
+				"name": "dependency-review.yml",
+				"path": ".github/workflows/dependency-review.yml",
+				"sha": "f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5"
+			},
+			"commit": {"sha": "a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6"}
+		}`, 201, nil
+	case "repos/paradisisland/maria/contents/.github/dependabot.yml":
+		return `{
+			"content": {
+				"name": "dependabot.yml",
+				"path": ".github/dependabot.yml",
+				"sha": "4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f"
+			},
+			"commit": {"sha": "d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3"}
+		}`, 201, nil
+	case "repos/paradisisland/maria/contents/.github/codeql/codeql-config.yml",
+		"repos/paradisisland/shiganshima/contents/.github/codeql/codeql-config.yml":
+		return `{
+			"content": {
+				"name": "codeql-config.yml",
+				"path": ".github/codeql/codeql-config.yml",
+				"sha": "3f1e0c8a7b6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+			},
+			"commit": {"sha": "c0ffee5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b"}
+		}`, 201, nil
+
+	case "repos/paradisisland/maria/vulnerability-alerts",
+		"repos/paradisisland/maria/automated-security-fixes":
+		return ``, 204, nil
+	case "repos/sandora-desert/liberio/vulnerability-alerts":
+		return `{}`, 422, &api.HTTPError{Message: "Vulnerability alerts are disabled for this organization", StatusCode: 422}
+
+	case "repos/paradisisland/maria/pulls/1347/update-branch":
+		return `{"message": "Updating pull request branch.", "url": "https://github.com/paradisisland/maria/pull/1347"}`, 202, nil
+	case "repos/paradisisland/rose/pulls/12/update-branch":
+		return `{}`, 422, &api.HTTPError{Message: "There are no new commits on the base branch.", StatusCode: 422}
+	case "repos/paradisisland/shiganshima/pulls/3/update-branch":
+		return `{}`, 422, &api.HTTPError{Message: "expected head sha didn't match current head ref.", StatusCode: 422}
+	case "repos/paradisisland/marley/pulls/1/update-branch":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+
 	case "repos/paradisisland/shiganshima/contents/.github/workflows/codeql.yml":
 		return `{
 			"content": {
@@ -155,6 +209,10 @@ func MockPutResponse(path string) (string, int, error) {
 // It returns a JSON string, a status code, and an error if the operation fails.
 func MockPostResponse(path string) (string, int, error) {
 	switch path {
+	case "orgs/paradisisland/code-security/configurations/17/attach":
+		return `{}`, 202, nil
+	case "orgs/paradisisland/code-security/configurations/18/attach":
+		return `{}`, 422, &api.HTTPError{Message: "Validation Failed", StatusCode: 422}
 	case "repos/paradisisland/maria/git/refs", "repos/paradisisland/shiganshima/git/refs":
 		return `{
             "ref": "refs/heads/gh-cli/codescanningworkflow",
@@ -175,10 +233,30 @@ func MockPostResponse(path string) (string, int, error) {
             "node_id": "MDExOlB1bGxSZXF1ZXN0MQ==",
             "html_url": "https://github.com/paradisisland/maria/pull/"
         }`, 201, nil
+	case "repos/paradisisland/shiganshima/pulls":
+		return `{
+            "url": "https://api.github.com/repos/paradisisland/shiganshima/pulls/1348",
+            "id": 2,
+            "html_url": "https://github.com/paradisisland/shiganshima/pull/1348"
+        }`, 201, nil
 	case "repos/paradisisland/rose/pulls":
 		return `{}`, 422, &api.HTTPError{Message: "Validation Failed", StatusCode: 422}
 	case "repos/paradisisland/marley/pulls":
 		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/issues/1347/comments":
+		return `{
+			"id": 1,
+			"html_url": "https://github.com/paradisisland/maria/pull/1347#issuecomment-1",
+			"body": "Reminder"
+		}`, 201, nil
+	case "repos/paradisisland/shiganshima/issues/3/comments":
+		return `{
+			"id": 4,
+			"html_url": "https://github.com/paradisisland/shiganshima/pull/3#issuecomment-4",
+			"body": "Reminder"
+		}`, 201, nil
+	case "repos/paradisisland/marley/issues/1/comments":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
 	default:
 		return "", 0, fmt.Errorf("MockPostResponse: Unexpected path: %s", path)
 	}
@@ -189,12 +267,34 @@ func MockPostResponse(path string) (string, int, error) {
 func MockPatchResponse(path string) (string, int, error) {
 	switch path {
 	case "repos/paradisisland/shiganshima/code-scanning/default-setup",
-		"repos/paradisisland/maria/code-scanning/default-setup":
+		"repos/paradisisland/maria/code-scanning/default-setup",
+		"repos/paradisisland/stohess/code-scanning/default-setup":
 		return `{}`, 200, nil
 	case "repos/paradisisland/rose/code-scanning/default-setup":
 		return `{}`, 403, &api.HTTPError{Message: "GHAS Not Enabled", StatusCode: 403}
 	case "repos/paradisisland/marley/code-scanning/default-setup":
 		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria", "repos/sandora-desert/liberio":
+		return `{
+			"full_name": "paradisisland/maria",
+			"security_and_analysis": {
+				"advanced_security": {"status": "enabled"},
+				"secret_scanning": {"status": "enabled"},
+				"secret_scanning_push_protection": {"status": "enabled"}
+			}
+		}`, 200, nil
+	case "repos/paradisisland/rose":
+		return `{}`, 422, &api.HTTPError{Message: "Advanced Security licenses exhausted", StatusCode: 422}
+	case "repos/paradisisland/sheena":
+		return `{}`, 422, &api.HTTPError{Message: "Secret scanning is not available for this repository", StatusCode: 422}
+	case "repos/paradisisland/maria/pulls/1347":
+		return `{"number": 1347, "state": "closed"}`, 200, nil
+	case "repos/paradisisland/rose/pulls/12":
+		return `{"number": 12, "state": "closed"}`, 200, nil
+	case "repos/paradisisland/shiganshima/pulls/3":
+		return `{"number": 3, "state": "open"}`, 200, nil
+	case "repos/paradisisland/sheena/pulls/21":
+		return `{"number": 21, "state": "closed"}`, 200, nil
 	default:
 		return "", 0, fmt.Errorf("MockPatchResponse: Unexpected path: %s", path)
 	}
@@ -204,6 +304,32 @@ func MockPatchResponse(path string) (string, int, error) {
 // It returns a JSON string, a status code, and an error if the operation fails.
 func MockOrgGetResponses(path string) (string, int, error) {
 	switch path {
+	case "orgs/paradisisland/settings/billing/advanced-security?per_page=100":
+		return `{
+			"total_advanced_security_committers": 39,
+			"total_count": 1,
+			"maximum_advanced_security_committers": 40,
+			"purchased_advanced_security_committers": 40,
+			"repositories": [
+				{
+					"name": "paradisisland/sheena",
+					"advanced_security_committers": 2,
+					"advanced_security_committers_breakdown": [
+						{"user_login": "eren", "last_pushed_date": "2023-01-19"},
+						{"user_login": "mikasa", "last_pushed_date": "2023-01-18"}
+					]
+				}
+			]
+		}`, 200, nil
+	case "orgs/sandora-desert/settings/billing/advanced-security?per_page=100":
+		return `{}`, 403, &api.HTTPError{Message: "Must have admin rights to Repository.", StatusCode: 403}
+	case "orgs/paradisisland/code-security/configurations?per_page=100":
+		return `[
+			{"id": 17, "name": "High risk", "target_type": "organization", "description": "Code scanning, secret scanning and Dependabot", "enforcement": "enforced"},
+			{"id": 18, "name": "Legacy", "target_type": "organization", "description": "Dependabot only", "enforcement": "unenforced"}
+		]`, 200, nil
+	case "orgs/atotallyrealorgname/code-security/configurations?per_page=100":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
 	case "orgs/paradisisland/repos":
 		return `[
             {
@@ -246,7 +372,7 @@ func MockOrgGetResponses(path string) (string, int, error) {
 func MockRepoGetResponses(path string) (string, int, error) {
 	switch path {
 	case "repos/paradisisland/maria":
-		return `{"full_name":"paradisisland/maria","name":"maria","default_branch":"main"}`, 200, nil
+		return `{"full_name":"paradisisland/maria","name":"maria","default_branch":"main","security_and_analysis":{"advanced_security":{"status":"enabled"}}}`, 200, nil
 	case "repos/paradisisland/marley":
 		return `[]`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
 	case "repos/paradisisland/maria/languages":
@@ -256,6 +382,16 @@ func MockRepoGetResponses(path string) (string, int, error) {
             "JavaScript": 300,
             "Python": 400
         }`, 200, nil
+	case "repos/paradisisland/sheena":
+		return `{"full_name":"paradisisland/sheena","name":"sheena","default_branch":"main","security_and_analysis":{"advanced_security":{"status":"enabled"}}}`, 200, nil
+	case "repos/paradisisland/sheena/languages":
+		return `{"Ruby": 100, "Python": 50}`, 200, nil
+	case "repos/paradisisland/shiganshima":
+		return `{"full_name":"paradisisland/shiganshima","name":"shiganshima","default_branch":"main","security_and_analysis":{"advanced_security":{"status":"enabled"}}}`, 200, nil
+	case "repos/paradisisland/shiganshima/languages":
+		return `{"C": 100}`, 200, nil
+	case "repos/paradisisland/stohess/languages":
+		return `{"Go": 100}`, 200, nil
 	case "repos/paradisisland/titanforest/languages":
 		return `{}`, 200, nil
 	case "repos/paradisisland/marley/languages":
@@ -271,9 +407,10 @@ func MockRepoGetResponses(path string) (string, int, error) {
             "updated_at": "2023-01-19T11:21:34Z",
             "schedule": "weekly"
           }`, 200, nil
-	case "repos/paradisisland/maria/code-scanning/default-setup":
+	case "repos/paradisisland/maria/code-scanning/default-setup", "repos/paradisisland/shiganshima/code-scanning/default-setup",
+		"repos/paradisisland/stohess/code-scanning/default-setup":
 		return `{"state": "not-configured"}`, 200, nil
-	case "repos/paradisisland/rose/code-scanning/default-setup":
+	case "repos/paradisisland/rose/code-scanning/default-setup", "repos/sandora-desert/liberio/code-scanning/default-setup":
 		return `{}`, 403, &api.HTTPError{Message: "GHAS Not Enabled", StatusCode: 403}
 	case "repos/paradisisland/marley/code-scanning/default-setup":
 		return `[]`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
@@ -303,9 +440,175 @@ func MockRepoGetResponses(path string) (string, int, error) {
 				}
 			}
 			  }`, 200, nil
+	case "repos/paradisisland/maria/branches/gh-cli/codescanningworkflow":
+		return `{
+			"name": "gh-cli/codescanningworkflow",
+			"protected": false,
+			"commit": {"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd"}
+		}`, 200, nil
+	case "repos/paradisisland/rose/branches/gh-cli/codescanningworkflow":
+		return `{
+			"name": "gh-cli/codescanningworkflow",
+			"protected": true,
+			"commit": {"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd"}
+		}`, 200, nil
+	case "repos/paradisisland/sheena/branches/gh-cli/codescanningworkflow", "repos/paradisisland/titanforest/branches/gh-cli/codescanningworkflow":
+		return `{
+			"name": "gh-cli/codescanningworkflow",
+			"protected": false,
+			"commit": {"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd"}
+		}`, 200, nil
+	case "repos/paradisisland/maria/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=open&per_page=100",
+		"repos/paradisisland/sheena/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=open&per_page=100":
+		return `[]`, 200, nil
+	case "repos/paradisisland/titanforest/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=open&per_page=100":
+		return `[
+			{
+				"number": 7,
+				"html_url": "https://github.com/paradisisland/titanforest/pull/7",
+				"state": "open",
+				"created_at": "2023-01-19T11:21:34Z",
+				"head": {"ref": "gh-cli/codescanningworkflow", "sha": "aa218f56b14c9653891f9e74264a383fa43fefbd"}
+			}
+		]`, 200, nil
+	case "repos/paradisisland/titanforest/actions/runs?branch=gh-cli/codescanningworkflow&head_sha=aa218f56b14c9653891f9e74264a383fa43fefbd&per_page=100":
+		return `{
+			"workflow_runs": [
+				{"id": 101, "name": "CodeQL", "path": ".github/workflows/codeql.yml", "conclusion": "failure"},
+				{"id": 102, "name": "CI", "path": ".github/workflows/ci.yml", "conclusion": "failure"}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/titanforest/actions/runs/101/jobs?per_page=100":
+		return `{
+			"jobs": [
+				{"id": 1011, "name": "Analyze (java-kotlin)", "html_url": "https://github.com/paradisisland/titanforest/actions/runs/101/job/1011", "conclusion": "failure", "runner_name": "GitHub Actions 2", "steps": [{"name": "Set up job"}, {"name": "Autobuild"}]},
+				{"id": 1012, "name": "Analyze (swift)", "html_url": "https://github.com/paradisisland/titanforest/actions/runs/101/job/1012", "conclusion": "failure", "runner_name": "", "steps": []},
+				{"id": 1013, "name": "Analyze (python)", "html_url": "https://github.com/paradisisland/titanforest/actions/runs/101/job/1013", "conclusion": "cancelled", "runner_name": "", "steps": []},
+				{"id": 1014, "name": "Analyze (go)", "html_url": "https://github.com/paradisisland/titanforest/actions/runs/101/job/1014", "conclusion": "timed_out", "runner_name": "", "steps": []},
+				{"id": 1015, "name": "Notify", "html_url": "https://github.com/paradisisland/titanforest/actions/runs/101/job/1015", "conclusion": "failure", "runner_name": "GitHub Actions 3", "steps": [{"name": "Set up job"}]}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/titanforest/actions/jobs/1011/logs":
+		return "2024-01-01T00:00:00Z ##[error]We were unable to automatically build your code.", 200, nil
+	case "repos/paradisisland/maria/actions/runs/42":
+		return `{"id": 42, "status": "completed", "conclusion": "success"}`, 200, nil
+	case "repos/paradisisland/marley/actions/runs/42":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/git/matching-refs/heads/gh-cli/":
+		return `[
+			{"ref": "refs/heads/gh-cli/codescanningworkflow", "object": {"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd", "type": "commit"}},
+			{"ref": "refs/heads/gh-cli/dependabot", "object": {"sha": "7638417db6d59f3c431d3e1f261cc637155684cd", "type": "commit"}},
+			{"ref": "refs/heads/gh-cli/nested/branch", "object": {"sha": "1acc419d4d6a9ce985db7be48c6349a0475975b5", "type": "commit"}}
+		]`, 200, nil
+	case "repos/paradisisland/marley/git/matching-refs/heads/gh-cli/":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/compare/main...gh-cli/codescanningworkflow":
+		return `{
+			"status": "ahead",
+			"ahead_by": 1,
+			"commits": [
+				{
+					"sha": "7638417db6d59f3c431d3e1f261cc637155684cd",
+					"commit": {
+						"author": {"name": "gh-cli add-files", "email": "security@yourcompany"},
+						"committer": {"name": "gh-cli add-files", "email": "security@yourcompany"}
+					}
+				}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/shiganshima/compare/main...gh-cli/codescanningworkflow":
+		return `{
+			"status": "ahead",
+			"ahead_by": 2,
+			"commits": [
+				{
+					"sha": "7638417db6d59f3c431d3e1f261cc637155684cd",
+					"commit": {
+						"author": {"name": "gh-cli add-files", "email": "security@yourcompany"},
+						"message": "AUTOMATED: commited CodeQL file"
+					},
+					"parents": [{"sha": "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"}]
+				},
+				{
+					"sha": "5f1e2d3c4b5a69788796a5b4c3d2e1f0a9b8c7d6",
+					"commit": {
+						"author": {"name": "Levi Ackerman", "email": "levi@paradisisland"},
+						"message": "Merge branch 'main' into gh-cli/codescanningworkflow"
+					},
+					"parents": [{"sha": "7638417db6d59f3c431d3e1f261cc637155684cd"}, {"sha": "9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c"}]
+				}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/sheena/compare/main...gh-cli/codescanningworkflow":
+		return `{
+			"status": "ahead",
+			"ahead_by": 2,
+			"commits": [
+				{
+					"sha": "7638417db6d59f3c431d3e1f261cc637155684cd",
+					"commit": {
+						"author": {"name": "gh-cli add-files", "email": "security@yourcompany"},
+						"committer": {"name": "gh-cli add-files", "email": "security@yourcompany"}
+					}
+				},
+				{
+					"sha": "1acc419d4d6a9ce985db7be48c6349a0475975b5",
+					"commit": {
+						"author": {"name": "Hange Zoe", "email": "hange@paradisisland"},
+						"committer": {"name": "Hange Zoe", "email": "hange@paradisisland"}
+					}
+				}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/trost/branches/gh-cli/codescanningworkflow":
+		return `{
+			"name": "gh-cli/codescanningworkflow",
+			"protected": false,
+			"commit": {"sha": "5f1e2d3c4b5a69788796a5b4c3d2e1f0a9b8c7d6"}
+		}`, 200, nil
+	case "repos/paradisisland/trost/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=open&per_page=100":
+		return `[]`, 200, nil
+	case "repos/paradisisland/trost/compare/main...gh-cli/codescanningworkflow":
+		return `{
+			"status": "diverged",
+			"ahead_by": 3,
+			"commits": [
+				{
+					"sha": "3c2b1a0f9e8d7c6b5a4938271605f4e3d2c1b0a9",
+					"commit": {
+						"author": {"name": "Rollout Bot", "email": "12345+rollout-bot@users.noreply.github.com"},
+						"message": "AUTOMATED: commited CodeQL file"
+					},
+					"author": {"login": "rollout-bot"},
+					"parents": [{"sha": "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"}]
+				},
+				{
+					"sha": "7638417db6d59f3c431d3e1f261cc637155684cd",
+					"commit": {
+						"author": {"name": "gh-cli add-files", "email": "security@yourcompany"},
+						"message": "AUTOMATED: commited CodeQL file"
+					},
+					"author": null,
+					"parents": [{"sha": "3c2b1a0f9e8d7c6b5a4938271605f4e3d2c1b0a9"}]
+				},
+				{
+					"sha": "5f1e2d3c4b5a69788796a5b4c3d2e1f0a9b8c7d6",
+					"commit": {
+						"author": {"name": "Rollout Bot", "email": "12345+rollout-bot@users.noreply.github.com"},
+						"message": "Merge branch 'main' into gh-cli/codescanningworkflow"
+					},
+					"author": {"login": "rollout-bot"},
+					"parents": [{"sha": "7638417db6d59f3c431d3e1f261cc637155684cd"}, {"sha": "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b"}]
+				}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/shiganshima/branches/gh-cli/codescanningworkflow":
+		return `{}`, 404, &api.HTTPError{Message: "Branch not found", StatusCode: 404}
+	case "repos/paradisisland/marley/branches/gh-cli/codescanningworkflow":
+		return `{}`, 500, &api.HTTPError{Message: "Internal Server Error", StatusCode: 500}
 	case "repos/paradisisland/marley/branches/main":
 		return `{}`, 500, &api.HTTPError{Message: "Internal Server Error", StatusCode: 500}
-	case "repos/paradisisland/maria/contents/.github/workflows/codeql.yml":
+	case "repos/paradisisland/maria/contents/.github/workflows/codeql.yml", "repos/paradisisland/stohess/contents/.github/workflows/codeql.yml":
 		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
 	case "repos/paradisisland/sheena/contents/.github/workflows/codeql.yml":
 		return `{
@@ -347,6 +650,246 @@ func MockRepoGetResponses(path string) (string, int, error) {
 			  }`, 200, nil
 	case "repos/paradisisland/marley/contents/.github/workflows/codeql.yml":
 		return `{}`, 404, nil
+	case "repos/paradisisland/shiganshima/contents/.github/workflows/codeql.yml?ref=gh-cli/codescanningworkflow":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "codeql.yml",
+			"path": ".github/workflows/codeql.yml",
+			"content": "bmFtZTogQ29kZVFM\nCg==\n",
+			"sha": "0ae040b692ec3e927163db2b984135aa3c088cba"
+		}`, 200, nil
+	case "repos/paradisisland/maria/contents/.github/workflows/codeql.yml?ref=gh-cli/codescanningworkflow":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/shiganshima/contents/.github/workflows/codeql.yml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "codeql.yml",
+			"path": ".github/workflows/codeql.yml",
+			"content": "bmFtZTogQ29kZVFMCm9uOgogIHB1c2g6CiAgICBicmFuY2hlczogWyBtYWluIF0Kam9iczoKICBhbmFseXplOgogICAgcnVucy1vbjogWyBzZWxmLWhvc3RlZCBdCiAgICBzdHJhdGVneToKICAgICAgbWF0cml4OgogICAgICAgIGxhbmd1YWdlOiBbIGdvIF0KICAgIHN0ZXBzOgogICAgICAtIHVzZXM6IGFjdGlvbnMvY2hlY2tvdXRAdjMKICAgICAgLSBydW46IG1ha2UgYnVpbGQK",
+			"sha": "3d21ec53a331a6f037a91c368710b99387d012c1"
+		}`, 200, nil
+	case "repos/paradisisland/maria/contents/.github/workflows/codeql.yml?ref=main":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/marley/contents/.github/workflows/codeql.yml?ref=gh-cli/codescanningworkflow":
+		return `{}`, 500, &api.HTTPError{Message: "Internal Server Error", StatusCode: 500}
+	case "repos/paradisisland/maria/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=all&per_page=100":
+		return `[
+			{
+				"number": 1347,
+				"title": "Automated PR: CodeQL workflow added",
+				"html_url": "https://github.com/paradisisland/maria/pull/1347",
+				"state": "open",
+				"created_at": "2023-01-19T11:21:34Z",
+				"closed_at": null,
+				"merged_at": null,
+				"head": {
+					"ref": "gh-cli/codescanningworkflow",
+					"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd"
+				}
+			}
+		]`, 200, nil
+	case "repos/paradisisland/sheena/pulls?head=paradisisland:gh-cli/codescanningmigration&state=all&per_page=100":
+		return `[
+			{
+				"number": 21,
+				"title": "Automated PR: Migrate to CodeQL default setup",
+				"html_url": "https://github.com/paradisisland/sheena/pull/21",
+				"state": "open",
+				"head": {"ref": "gh-cli/codescanningmigration", "sha": "1acc419d4d6a9ce985db7be48c6349a0475975b5"}
+			}
+		]`, 200, nil
+	case "repos/paradisisland/shiganshima/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=all&per_page=100":
+		return `{}`, 502, &api.HTTPError{Message: "Bad Gateway", StatusCode: 502}
+	case "repos/paradisisland/rose/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=all&per_page=100":
+		return `[]`, 200, nil
+	case "repos/paradisisland/marley/pulls?head=paradisisland:gh-cli/codescanningworkflow&state=all&per_page=100":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/issues/1347/comments?per_page=100":
+		return `[
+			{"id": 1, "body": "<!-- gh-add-files:reminder -->\nThis pull request has been open for 14 days.", "created_at": "2026-10-10T09:00:00Z"}
+		]`, 200, nil
+	case "repos/paradisisland/shiganshima/issues/3/comments?per_page=100":
+		return `[
+			{"id": 2, "body": "<!-- gh-add-files:reminder -->\nThis pull request has been open for 14 days.", "created_at": "2026-01-10T09:00:00Z"},
+			{"id": 3, "body": "We will look at it next sprint", "created_at": "2026-10-10T09:00:00Z"}
+		]`, 200, nil
+	case "repos/paradisisland/marley/issues/1/comments?per_page=100":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/pulls/1347/reviews?per_page=100":
+		return `[
+			{"user": {"login": "eren"}, "state": "CHANGES_REQUESTED"},
+			{"user": {"login": "mikasa"}, "state": "APPROVED"},
+			{"user": {"login": "eren"}, "state": "COMMENTED"},
+			{"user": {"login": "eren"}, "state": "APPROVED"}
+		]`, 200, nil
+	case "repos/paradisisland/rose/pulls/12/reviews?per_page=100":
+		return `[
+			{"user": {"login": "armin"}, "state": "APPROVED"},
+			{"user": {"login": "levi"}, "state": "CHANGES_REQUESTED"}
+		]`, 200, nil
+	case "repos/paradisisland/shiganshima/pulls/3/reviews?per_page=100":
+		return `[]`, 200, nil
+	case "repos/paradisisland/marley/pulls/1/reviews?per_page=100":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/commits/aa218f56b14c9653891f9e74264a383fa43fefbd/check-runs?per_page=100":
+		return `{
+			"total_count": 4,
+			"check_runs": [
+				{"name": "code_analysis / Analyze (go)", "status": "completed", "conclusion": "success"},
+				{"name": "code_analysis / Analyze (java)", "status": "completed", "conclusion": "failure"},
+				{"name": "CodeQL", "status": "in_progress", "conclusion": null},
+				{"name": "lint", "status": "completed", "conclusion": "success"}
+			]
+		}`, 200, nil
+	case "repos/paradisisland/marley/commits/aa218f56b14c9653891f9e74264a383fa43fefbd/check-runs?per_page=100":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/git/trees/main?recursive=1":
+		return `{
+			"sha": "9fb037999f264ba9a7fc6274d15fa3ae2ab98312",
+			"tree": [
+				{"path": "cmd", "type": "tree"},
+				{"path": "cmd/main.go", "type": "blob"},
+				{"path": "vendor", "type": "tree"},
+				{"path": "vendor/github.com/spf13/cobra/vendor", "type": "tree"},
+				{"path": "web/node_modules", "type": "tree"},
+				{"path": "web/node_modules/lodash/node_modules", "type": "tree"},
+				{"path": "web/package.json", "type": "blob"},
+				{"path": "ios/Pods", "type": "tree"},
+				{"path": "docs/vendor.md", "type": "blob"}
+			],
+			"truncated": false
+		}`, 200, nil
+	case "repos/paradisisland/marley/git/trees/main?recursive=1":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/contents/.github/codeql/codeql-config.yml?ref=gh-cli/codescanningworkflow",
+		"repos/paradisisland/shiganshima/contents/.github/codeql/codeql-config.yml?ref=gh-cli/codescanningworkflow",
+		"repos/paradisisland/maria/contents/.github/codeql/codeql-config.yml?ref=main":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/shiganshima/contents/.github/codeql/codeql-config.yml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "codeql-config.yml",
+			"path": ".github/codeql/codeql-config.yml",
+			"content": "bmFtZTogQ29kZVFMIGNvbmZpZwo=",
+			"sha": "6a1f3c5e7b9d0f2a4c6e8b0d2f4a6c8e0b2d4f6a"
+		}`, 200, nil
+	case "repos/paradisisland/marley/contents/.github/codeql/codeql-config.yml?ref=main":
+		return `{}`, 500, &api.HTTPError{Message: "Internal Server Error", StatusCode: 500}
+	case "repos/paradisisland/maria/contents/.github/dependabot.yml?ref=main",
+		"repos/paradisisland/maria/contents/.github/dependabot.yaml?ref=main",
+		"repos/paradisisland/shiganshima/contents/.github/dependabot.yml?ref=main":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/shiganshima/contents/.github/dependabot.yaml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "dependabot.yaml",
+			"path": ".github/dependabot.yaml",
+			"content": "dmVyc2lvbjogMgp1cGRhdGVzOgogIC0gcGFja2FnZS1lY29zeXN0ZW06IGdvbW9kCiAgICBkaXJlY3Rvcnk6IC8KICAgIHNjaGVkdWxlOgogICAgICBpbnRlcnZhbDogZGFpbHkK",
+			"sha": "b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0"
+		}`, 200, nil
+	case "repos/paradisisland/maria/contents/.github/workflows/dependency-review.yml?ref=main":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/shiganshima/contents/.github/workflows/dependency-review.yml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "dependency-review.yml",
+			"path": ".github/workflows/dependency-review.yml",
+			"content": "bmFtZTogRGVwZW5kZW5jeSBSZXZpZXcK",
+			"sha": "e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4"
+		}`, 200, nil
+	case "repos/paradisisland/maria/code-security-configuration":
+		return `{
+			"status": "attached",
+			"configuration": {"id": 17, "name": "High risk", "target_type": "organization", "enforcement": "enforced"}
+		}`, 200, nil
+	case "repos/paradisisland/rose/code-security-configuration":
+		return ``, 204, nil
+	case "repos/paradisisland/shiganshima/code-security-configuration":
+		return `{
+			"status": "attaching",
+			"configuration": {"id": 17, "name": "High risk", "target_type": "organization", "enforcement": "enforced"}
+		}`, 200, nil
+	case "repos/paradisisland/marley/code-security-configuration":
+		return `{}`, 403, &api.HTTPError{Message: "Resource not accessible by integration", StatusCode: 403}
+	case "repos/paradisisland/maria/contents/.github/workflows?ref=main":
+		return `[
+			{"name": "build.yml", "path": ".github/workflows/build.yml", "type": "file"},
+			{"name": "codeql-analysis.yml", "path": ".github/workflows/codeql-analysis.yml", "type": "file"},
+			{"name": "README.md", "path": ".github/workflows/README.md", "type": "file"}
+		]`, 200, nil
+	case "repos/paradisisland/rose/contents/.github/workflows?ref=main":
+		return `[
+			{"name": "build.yml", "path": ".github/workflows/build.yml", "type": "file"},
+			{"name": "security.yaml", "path": ".github/workflows/security.yaml", "type": "file"}
+		]`, 200, nil
+	case "repos/paradisisland/shiganshima/contents/.github/workflows?ref=main", "repos/paradisisland/stohess/contents/.github/workflows?ref=main":
+		return `[
+			{"name": "build.yml", "path": ".github/workflows/build.yml", "type": "file"}
+		]`, 200, nil
+	case "repos/paradisisland/marley/contents/.github/workflows?ref=main":
+		return `{}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/paradisisland/maria/contents/.github/workflows/build.yml?ref=main",
+		"repos/paradisisland/rose/contents/.github/workflows/build.yml?ref=main",
+		"repos/paradisisland/shiganshima/contents/.github/workflows/build.yml?ref=main",
+		"repos/paradisisland/stohess/contents/.github/workflows/build.yml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "build.yml",
+			"path": ".github/workflows/build.yml",
+			"content": "bmFtZTogQnVpbGQKb246IHB1c2gKam9iczoKICBidWlsZDoKICAgIHJ1bnMtb246IHVidW50dS1sYXRlc3QKICAgIHN0ZXBzOgogICAgICAtIHVzZXM6IGFjdGlvbnMvY2hlY2tvdXRAdjQKICAgICAgLSBydW46IG1ha2UK",
+			"sha": "5f3a9c1d2b7e8f40a6c3d1e2f9b8a7c6d5e4f3a2"
+		}`, 200, nil
+	case "repos/paradisisland/maria/contents/.github/workflows/codeql-analysis.yml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "codeql-analysis.yml",
+			"path": ".github/workflows/codeql-analysis.yml",
+			"content": "bmFtZTogQ29kZVFMCm9uOiBwdXNoCmpvYnM6CiAgYW5hbHl6ZToKICAgIHJ1bnMtb246IHVidW50dS1sYXRlc3QKICAgIHN0ZXBzOgogICAgICAtIHVzZXM6IGFjdGlvbnMvY2hlY2tvdXRAdjQKICAgICAgLSB1c2VzOiBnaXRodWIvY29kZXFsLWFjdGlvbi9pbml0QHYzCiAgICAgIC0gdXNlczogZ2l0aHViL2NvZGVxbC1hY3Rpb24vYW5hbHl6ZUB2Mwo=",
+			"sha": "2c4e6a8b0d1f3e5a7c9b1d3f5e7a9c0b2d4f6e8a"
+		}`, 200, nil
+	case "repos/paradisisland/rose/contents/.github/workflows/security.yaml?ref=main":
+		return `{
+			"type": "file",
+			"encoding": "base64",
+			"name": "security.yaml",
+			"path": ".github/workflows/security.yaml",
+			"content": "bmFtZTogU2VjdXJpdHkKb246IHB1c2gKam9iczoKICBzY2FuOgogICAgdXNlczogcGFyYWRpc2lzbGFuZC9zZWN1cml0eS8uZ2l0aHViL3dvcmtmbG93cy9zY2FuLnltbEBtYWluCg==",
+			"sha": "9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d"
+		}`, 200, nil
+	case "repos/advanced-security-demo/central-repo-test":
+		return `{"full_name": "advanced-security-demo/central-repo-test", "visibility": "private"}`, 200, nil
+	case "repos/advanced-security-demo/central-repo-test/actions/permissions/access":
+		return `{"access_level": "organization"}`, 200, nil
+	case "repos/advanced-security-demo/central-repo-test/contents/.github/workflows/code_analysis.yml?ref=main":
+		return `{"type": "file", "name": "code_analysis.yml", "path": ".github/workflows/code_analysis.yml"}`, 200, nil
+	case "repos/advanced-security-demo/central-repo-test/contents/.github/workflows/code_analysis.yaml?ref=main":
+		return `{"message": "Not Found"}`, 404, &api.HTTPError{Message: "Not Found", StatusCode: 404}
+	case "repos/actions/checkout":
+		return `{"full_name": "actions/checkout", "visibility": "public"}`, 200, nil
+	case "repos/actions/checkout/commits/v4":
+		return `{"sha": "11bd71901bbe5b1630ceea73d27597364c9af683"}`, 200, nil
+	case "repos/github/codeql-action/commits/v3":
+		return `{"sha": "662472033e021d55d94146f66f6058822b0b39fd"}`, 200, nil
+	case "repos/actions/checkout/commits/v9":
+		return `{"message": "No commit found for SHA: v9"}`, 422, &api.HTTPError{Message: "No commit found for SHA: v9", StatusCode: 422}
+	case "repos/paradisisland/maria/code-scanning/analyses?tool_name=CodeQL&per_page=1":
+		return `[
+			{"id": 201, "ref": "refs/heads/main", "created_at": "2023-01-19T11:21:34Z", "tool": {"name": "CodeQL"}}
+		]`, 200, nil
+	case "repos/paradisisland/shiganshima/code-scanning/analyses?tool_name=CodeQL&per_page=1",
+		"repos/paradisisland/sheena/code-scanning/analyses?tool_name=CodeQL&per_page=1":
+		return `[]`, 200, nil
+	case "repos/paradisisland/rose/code-scanning/analyses?tool_name=CodeQL&per_page=1":
+		return `{"message": "no analysis found"}`, 404, &api.HTTPError{Message: "no analysis found", StatusCode: 404}
+	case "repos/paradisisland/marley/code-scanning/analyses?tool_name=CodeQL&per_page=1":
+		return `{}`, 403, &api.HTTPError{Message: "Advanced Security must be enabled for this repository to use code scanning.", StatusCode: 403}
 	default:
 		return "", 0, fmt.Errorf("MockRepoGetResponses: Unexpected path: %s", path)
 	}
@@ -359,6 +902,22 @@ func MockGetResponse(path string) (string, int, error) {
 		return MockOrgGetResponses(path)
 	}
 
+	//Authenticated user
+	if path == "user" {
+		return `{"login": "rollout-bot"}`, 200, nil
+	}
+
+	//Commits since a date that depends on the day the tests run
+	if strings.HasPrefix(path, "repos/paradisisland/maria/commits?since=") {
+		return `[
+			{"sha": "7638417db6d59f3c431d3e1f261cc637155684cd", "author": {"login": "eren"}},
+			{"sha": "1acc419d4d6a9ce985db7be48c6349a0475975b5", "author": {"login": "armin"}},
+			{"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd", "author": {"login": "armin"}},
+			{"sha": "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d", "author": {"login": "levi"}},
+			{"sha": "95b966ae1c166bd92f8ae7d1c313e738c731dfc3", "author": null}
+		]`, 200, nil
+	}
+
 	//Get Branches
 	if strings.HasPrefix(path, "repos/") {
 		return MockRepoGetResponses(path)
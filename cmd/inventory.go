@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SetupDefault  = "default"
+	SetupAdvanced = "advanced"
+	SetupNone     = "none"
+)
+
+// InventoryEntry is the code scanning coverage of a single repository.
+type InventoryEntry struct {
+	Repository       string     `json:"repository"`
+	Languages        []string   `json:"languages"`
+	AdvancedSecurity string     `json:"advanced_security"`
+	Setup            string     `json:"setup"`
+	LastAnalysis     *time.Time `json:"last_analysis,omitempty"`
+}
+
+func init() {
+	inventoryCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to report the code scanning coverage of")
+	inventoryCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	inventoryCmd.MarkFlagsMutuallyExclusive("csv", "organization")
+	inventoryCmd.PersistentFlags().StringVar(&CentralWorkflow, "central-workflow", "", "specify the central reusable CodeQL workflow e.g. 'my-org/security/.github/workflows/codeql.yml', calls to it count as advanced setup")
+	inventoryCmd.PersistentFlags().StringVar(&AnalysisMaxAge, "analysis-max-age", "30d", "count repositories with CodeQL results uploaded within this age as advanced setup e.g. 30d, an empty value disables the check")
+	inventoryCmd.PersistentFlags().StringVarP(&OutputFormat, "format", "F", "table", "specify the output format: table, json or csv")
+	inventoryCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Report the code scanning coverage of repositories",
+	Long:  "Report the CodeQL languages, Advanced Security status, code scanning setup and last CodeQL analysis of each repo in organisation, csv file or argument list without changing anything",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		// logs go to stderr so the table, JSON or csv output can be piped
+		logFile, err := setupLogging(LogFile, os.Stderr)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		// check if organization or csv file is provided
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		if OutputFormat != "table" && OutputFormat != "json" && OutputFormat != "csv" {
+			log.Fatalf("ERROR: Unknown output format %s, must be table, json or csv\n", OutputFormat)
+		}
+
+		if len(AnalysisMaxAge) > 0 {
+			if _, err := parseAge(AnalysisMaxAge); err != nil {
+				log.Fatalln("ERROR: Invalid analysis-max-age flag: ", err)
+			}
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var inventory []InventoryEntry
+		for _, repo := range repos {
+			entry, err := repo.getInventoryEntry(client)
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			inventory = append(inventory, entry)
+		}
+
+		if OutputFormat == "json" {
+			err = printJSON(os.Stdout, inventory)
+		} else {
+			var rows [][]string
+			for _, entry := range inventory {
+				lastAnalysis := ""
+				if entry.LastAnalysis != nil {
+					lastAnalysis = entry.LastAnalysis.Format(time.RFC3339)
+				}
+				rows = append(rows, []string{entry.Repository, strings.Join(entry.Languages, " "), entry.AdvancedSecurity, entry.Setup, lastAnalysis})
+			}
+			headers := []string{"REPOSITORY", "LANGUAGES", "ADVANCED SECURITY", "SETUP", "LAST ANALYSIS"}
+			if OutputFormat == "csv" {
+				err = printCsv(os.Stdout, headers, rows)
+			} else {
+				err = printTable(os.Stdout, headers, rows)
+			}
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+	},
+}
+
+// getInventoryEntry reads the code scanning coverage of the repository.
+// Only GET requests are made, so Advanced Security is never enabled and default setup is never changed.
+func (repo *Repository) getInventoryEntry(client Client) (InventoryEntry, error) {
+	entry := InventoryEntry{Repository: repo.FullName, Setup: SetupNone}
+
+	languages, err := repo.GetCodeqlLanguages(client)
+	if err != nil {
+		return entry, err
+	}
+	entry.Languages = languages
+
+	entry.AdvancedSecurity, err = repo.getAdvancedSecurityStatus(client)
+	if err != nil {
+		return entry, err
+	}
+
+	// a 403 means Advanced Security is disabled, so neither setup can be enabled
+	isDefaultSetupEnabled, statusCode, err := repo.getDefaultSetupState(client)
+	if err != nil && statusCode != 403 {
+		return entry, err
+	}
+
+	isCodeQLEnabled, _, err := repo.doesCodeqlWorkflowExist(client)
+	if err != nil {
+		return entry, err
+	}
+
+	if isDefaultSetupEnabled {
+		entry.Setup = SetupDefault
+	} else if isCodeQLEnabled {
+		entry.Setup = SetupAdvanced
+	} else if statusCode != 403 {
+		// CodeQL can also run from a workflow with another filename, a central workflow or another CI system
+		existing, _, err := repo.detectExistingCodeql(client)
+		if err != nil {
+			return entry, err
+		}
+		if len(existing) > 0 {
+			entry.Setup = SetupAdvanced
+		}
+	}
+
+	if statusCode != 403 {
+		entry.LastAnalysis, err = repo.getLastCodeqlAnalysis(client)
+		if err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRepository_getInventoryEntry(t *testing.T) {
+	lastAnalysis := time.Date(2023, 1, 19, 11, 21, 34, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fullName string
+		want     InventoryEntry
+		wantErr  bool
+	}{
+		{
+			name:     "When the repository runs CodeQL from a workflow that is not codeql.yml",
+			fullName: "paradisisland/maria",
+			want: InventoryEntry{
+				Repository:       "paradisisland/maria",
+				Languages:        []string{"Go", "Java", "JavaScript", "Python"},
+				AdvancedSecurity: "enabled",
+				Setup:            SetupAdvanced,
+				LastAnalysis:     &lastAnalysis,
+			},
+			wantErr: false,
+		},
+		{
+			name:     "When the repository has a codeql.yml workflow",
+			fullName: "paradisisland/shiganshima",
+			want: InventoryEntry{
+				Repository:       "paradisisland/shiganshima",
+				Languages:        []string{"C"},
+				AdvancedSecurity: "enabled",
+				Setup:            SetupAdvanced,
+			},
+			wantErr: false,
+		},
+		{
+			name:     "When the repository has default setup enabled",
+			fullName: "paradisisland/sheena",
+			want: InventoryEntry{
+				Repository:       "paradisisland/sheena",
+				Languages:        []string{"Python", "Ruby"},
+				AdvancedSecurity: "enabled",
+				Setup:            SetupDefault,
+			},
+			wantErr: false,
+		},
+		{
+			name:     "When the languages of the repository cannot be read",
+			fullName: "paradisisland/marley",
+			want:     InventoryEntry{Repository: "paradisisland/marley", Setup: SetupNone},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.getInventoryEntry(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getInventoryEntry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.getInventoryEntry() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var MergeWorkflow bool
+
+// textEdit replaces the lines from start to end, both 1-based and inclusive, with new lines.
+type textEdit struct {
+	start int
+	end   int
+	lines []string
+}
+
+// yamlBlock is a mapping entry and the lines it spans in the file.
+type yamlBlock struct {
+	key   *yaml.Node
+	value *yaml.Node
+	start int
+	end   int
+}
+
+// mergeWorkflow updates the keys add-files manages in the existing workflow to match the rendered one, and keeps everything else.
+// The managed keys are the triggers, the refs of actions and reusable workflows, and the language matrix.
+// The existing file is edited line by line so the diff only shows the managed keys that changed.
+func mergeWorkflow(existing []byte, rendered []byte) ([]byte, error) {
+	existingRoot, err := parseWorkflowDocument(existing)
+	if err != nil {
+		log.Printf("ERROR: Unable to parse the existing workflow file\n")
+		return nil, err
+	}
+	renderedRoot, err := parseWorkflowDocument(rendered)
+	if err != nil {
+		log.Printf("ERROR: Unable to parse the new workflow file\n")
+		return nil, err
+	}
+
+	existingLines := strings.Split(string(existing), "\n")
+	renderedLines := strings.Split(string(rendered), "\n")
+
+	var edits []textEdit
+
+	// triggers
+	if from, ok := findYamlBlock(existingRoot, existingLines, "on"); ok {
+		if to, ok := findYamlBlock(renderedRoot, renderedLines, "on"); ok {
+			edits = append(edits, replaceBlock(from, to, renderedLines))
+		}
+	}
+
+	// language matrix, matched by job name or else taken from the first job of the new workflow that has one
+	var renderedLanguages *yamlBlock
+	renderedJobs := mappingValue(renderedRoot, "jobs")
+	if renderedJobs != nil {
+		for i := 0; i+1 < len(renderedJobs.Content); i += 2 {
+			if block, ok := findYamlBlock(renderedRoot, renderedLines, "jobs", renderedJobs.Content[i].Value, "strategy", "matrix", "language"); ok {
+				renderedLanguages = &block
+				break
+			}
+		}
+	}
+	if existingJobs := mappingValue(existingRoot, "jobs"); existingJobs != nil && renderedLanguages != nil {
+		for i := 0; i+1 < len(existingJobs.Content); i += 2 {
+			job := existingJobs.Content[i].Value
+			from, ok := findYamlBlock(existingRoot, existingLines, "jobs", job, "strategy", "matrix", "language")
+			if !ok {
+				continue
+			}
+			to := *renderedLanguages
+			if block, ok := findYamlBlock(renderedRoot, renderedLines, "jobs", job, "strategy", "matrix", "language"); ok {
+				to = block
+			}
+			edits = append(edits, replaceBlock(from, to, renderedLines))
+		}
+	}
+
+	// action and reusable workflow refs
+	refs := map[string]*yaml.Node{}
+	for _, uses := range workflowUses(renderedRoot) {
+		refs[strings.Split(uses.Value, "@")[0]] = uses
+	}
+	for _, uses := range workflowUses(existingRoot) {
+		ref, ok := refs[strings.Split(uses.Value, "@")[0]]
+		if !ok || ref.Value == uses.Value {
+			continue
+		}
+		line := existingLines[uses.Line-1]
+		column := uses.Column - 1
+		if column > len(line) || !strings.Contains(line[column:], uses.Value) {
+			continue
+		}
+		start := column + strings.Index(line[column:], uses.Value)
+		rest := line[start+len(uses.Value):]
+		// the version comment of a pinned ref names the old version, so it is replaced with the comment of the new ref
+		if len(uses.LineComment) > 0 {
+			if comment := strings.Index(rest, "#"); comment >= 0 {
+				rest = strings.TrimRight(rest[:comment], " \t")
+			}
+		}
+		if len(ref.LineComment) > 0 {
+			rest += " " + ref.LineComment
+		}
+		edits = append(edits, textEdit{start: uses.Line, end: uses.Line, lines: []string{line[:start] + ref.Value + rest}})
+	}
+
+	return applyTextEdits(existingLines, edits)
+}
+
+func parseWorkflowDocument(content []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) <= 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the workflow file is not a YAML mapping")
+	}
+	return document.Content[0], nil
+}
+
+// findYamlBlock finds the entry at the path of keys and the lines it spans.
+// An entry ends before the next key of its mapping, or where its parent ends, without the blank and comment lines in between.
+func findYamlBlock(root *yaml.Node, lines []string, path ...string) (yamlBlock, bool) {
+	node := root
+	end := len(lines)
+	var block yamlBlock
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+			return block, false
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != key {
+				continue
+			}
+			if i+2 < len(node.Content) {
+				end = node.Content[i+2].Line - 1
+			}
+			block = yamlBlock{key: node.Content[i], value: node.Content[i+1], start: node.Content[i].Line}
+			found = true
+			break
+		}
+		if !found {
+			return block, false
+		}
+		node = block.value
+	}
+
+	block.end = trimBlockEnd(lines, block.start, end)
+	return block, true
+}
+
+// replaceBlock replaces the lines of an existing block with the lines of the new block, indented like the existing one.
+func replaceBlock(from yamlBlock, to yamlBlock, toLines []string) textEdit {
+	shift := from.key.Column - to.key.Column
+	var lines []string
+	for _, line := range toLines[to.start-1 : to.end] {
+		if shift > 0 && len(line) > 0 {
+			line = strings.Repeat(" ", shift) + line
+		} else if shift < 0 {
+			line = line[len(line)-len(strings.TrimPrefix(line, strings.Repeat(" ", -shift))):]
+		}
+		lines = append(lines, line)
+	}
+	return textEdit{start: from.start, end: from.end, lines: lines}
+}
+
+// workflowUses returns the uses scalars of the jobs and their steps.
+func workflowUses(root *yaml.Node) []*yaml.Node {
+	var uses []*yaml.Node
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return uses
+	}
+	for i := 1; i < len(jobs.Content); i += 2 {
+		job := jobs.Content[i]
+		if value := mappingValue(job, "uses"); value != nil && value.Kind == yaml.ScalarNode {
+			uses = append(uses, value)
+		}
+		steps := mappingValue(job, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, step := range steps.Content {
+			if value := mappingValue(step, "uses"); value != nil && value.Kind == yaml.ScalarNode {
+				uses = append(uses, value)
+			}
+		}
+	}
+	return uses
+}
+
+// applyTextEdits applies the edits from the bottom of the file up so the line numbers of the others stay valid.
+func applyTextEdits(lines []string, edits []textEdit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for i := 1; i < len(edits); i++ {
+		if edits[i].end >= edits[i-1].start {
+			return nil, errors.New("the managed keys of the workflow file overlap")
+		}
+	}
+
+	result := append([]string{}, lines...)
+	for _, edit := range edits {
+		tail := append([]string{}, result[edit.end:]...)
+		result = append(append(result[:edit.start-1], edit.lines...), tail...)
+	}
+	return []byte(strings.Join(result, "\n")), nil
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"testing"
+)
+
+const renderedWorkflow = `name: "CodeQL"
+
+on:
+  push:
+    branches: [ "main" ]
+  pull_request:
+    branches: [ "main" ]
+  schedule:
+    - cron: '30 1 * * 0'
+
+jobs:
+  analyze:
+    name: Analyze
+    runs-on: ubuntu-latest
+    strategy:
+      fail-fast: false
+      matrix:
+        language: [ 'go', 'python' ]
+    steps:
+    - name: Checkout repository
+      uses: actions/checkout@v4
+    - name: Initialize CodeQL
+      uses: github/codeql-action/init@v3
+      with:
+        languages: ${{ matrix.language }}
+    - name: Perform CodeQL Analysis
+      uses: github/codeql-action/analyze@v3
+`
+
+func Test_mergeWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		rendered string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "When the existing workflow has custom steps and runners",
+			existing: `name: CodeQL
+on:
+  push:
+    branches: [ main ]
+
+# analysis runs on our own runners
+jobs:
+  analyze:
+    runs-on: [ self-hosted, linux ]
+    strategy:
+      matrix:
+        language:
+          - go
+    steps:
+      - uses: actions/checkout@v3
+      - run: make build
+      - uses: "github/codeql-action/init@v2"
+        with:
+          languages: ${{ matrix.language }}
+      - uses: github/codeql-action/analyze@v2
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-go@v5
+`,
+			rendered: renderedWorkflow,
+			want: `name: CodeQL
+on:
+  push:
+    branches: [ "main" ]
+  pull_request:
+    branches: [ "main" ]
+  schedule:
+    - cron: '30 1 * * 0'
+
+# analysis runs on our own runners
+jobs:
+  analyze:
+    runs-on: [ self-hosted, linux ]
+    strategy:
+      matrix:
+        language: [ 'go', 'python' ]
+    steps:
+      - uses: actions/checkout@v4
+      - run: make build
+      - uses: "github/codeql-action/init@v3"
+        with:
+          languages: ${{ matrix.language }}
+      - uses: github/codeql-action/analyze@v3
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-go@v5
+`,
+			wantErr: false,
+		},
+		{
+			name: "When the existing workflow calls a reusable workflow",
+			existing: `on: [ push ]
+jobs:
+  codeql:
+    uses: my-org/security/.github/workflows/codeql.yml@v1
+    secrets: inherit
+`,
+			rendered: `on: [ push, pull_request ]
+jobs:
+  codeql:
+    uses: my-org/security/.github/workflows/codeql.yml@v2
+`,
+			want: `on: [ push, pull_request ]
+jobs:
+  codeql:
+    uses: my-org/security/.github/workflows/codeql.yml@v2
+    secrets: inherit
+`,
+			wantErr: false,
+		},
+		{
+			name: "When the refs are pinned to commit SHAs",
+			existing: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v4.1.1
+      - uses: "github/codeql-action/init@v2" # pinned later
+      - uses: github/codeql-action/analyze@v2
+`,
+			rendered: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4
+      - uses: github/codeql-action/init@662472033e021d55d94146f66f6058822b0b39fd # v3
+      - uses: github/codeql-action/analyze@662472033e021d55d94146f66f6058822b0b39fd # v3
+`,
+			want: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4
+      - uses: "github/codeql-action/init@662472033e021d55d94146f66f6058822b0b39fd" # v3
+      - uses: github/codeql-action/analyze@662472033e021d55d94146f66f6058822b0b39fd # v3
+`,
+			wantErr: false,
+		},
+		{
+			name: "When a pinned ref is replaced with a tag",
+			existing: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v4.1.1
+`,
+			rendered: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@v4
+`,
+			want: `on: [ push ]
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@v4
+`,
+			wantErr: false,
+		},
+		{
+			name:     "When the existing workflow is not valid YAML",
+			existing: "on: [ push\n",
+			rendered: renderedWorkflow,
+			want:     "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeWorkflow([]byte(tt.existing), []byte(tt.rendered))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mergeWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeWorkflow() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
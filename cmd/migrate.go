@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// ModeMigrate converts repositories from advanced setup back to default setup.
+const ModeMigrate = "migrate"
+
+// migrationBranch is the branch the removal of the CodeQL workflow is committed to.
+const migrationBranch = "gh-cli/codescanningmigration"
+
+// StatusMigrationPullRequest is the report status of a repository where a migration PR was raised.
+const StatusMigrationPullRequest = "migration-pull-request"
+
+// migrateToDefaultSetup raises a PR that removes the CodeQL workflow so default setup can be enabled once it is merged.
+// It returns the report status of the repository and the PR that was raised.
+func (repo *Repository) migrateToDefaultSetup(client Client) (string, string, error) {
+	isDefaultSetupEnabled, err := repo.checkDefaultSetupEnabled(client)
+	if err != nil {
+		return "", "", err
+	}
+	if isDefaultSetupEnabled {
+		log.Printf("Default setup already enabled for this repository: %s, skipping migration.", repo.FullName)
+		return StatusDefaultSetup, "", nil
+	}
+
+	workflows, err := repo.migratedWorkflows(client)
+	if err != nil {
+		return "", "", err
+	}
+	if len(workflows) <= 0 {
+		log.Printf("CodeQL workflow file does not exist for this repository: %s, skipping migration.", repo.FullName)
+		return StatusNoWorkflow, "", nil
+	}
+
+	newbranchref, err := repo.createOrReplaceBranch(client, migrationBranch)
+	if err != nil {
+		return "", "", err
+	}
+	log.Printf("Ref created succesfully at : %s\n", newbranchref)
+
+	// default setup can only be enabled once no workflow runs CodeQL anymore
+	for _, workflow := range workflows {
+		_, sha, err := repo.getFileContent(client, workflow, repo.DefaultBranch)
+		if err != nil {
+			return "", "", err
+		}
+		err = repo.deleteFile(client, workflow, migrationBranch, sha, "AUTOMATED: removed CodeQL workflow file")
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	createdPR, err := repo.openPullRequest(client, migrationBranch, "Automated PR: migrate CodeQL to default setup", migrationPullRequestBody)
+	if err != nil {
+		return "", "", err
+	}
+	if len(createdPR) <= 0 {
+		return "", "", errors.New("Something went wrong when creating new pull request")
+	}
+	log.Printf("Successfully raised pull request %s on branch %s in repository %s\n", createdPR, newbranchref, repo.FullName)
+	return StatusMigrationPullRequest, createdPR, nil
+}
+
+// completeMigration enables default setup once the migration PR has been merged and the CodeQL workflow is gone from the default branch.
+// It returns false when the PR is not merged yet or default setup was already enabled.
+func (repo *Repository) completeMigration(client Client, pullRequest PullRequestStatus) (bool, error) {
+	if pullRequest.State != "merged" {
+		log.Printf("Migration pull request %s is not merged yet, default setup is not enabled for repository %s\n", pullRequest.URL, repo.FullName)
+		return false, nil
+	}
+
+	isDefaultSetupEnabled, err := repo.checkDefaultSetupEnabled(client)
+	if err != nil {
+		return false, err
+	}
+	if isDefaultSetupEnabled {
+		return false, nil
+	}
+
+	// a workflow may have been added back since the PR was merged
+	workflows, err := repo.migratedWorkflows(client)
+	if err != nil {
+		return false, err
+	}
+	if len(workflows) > 0 {
+		log.Printf("ERROR: The CodeQL workflow %s still exists on the default branch of repository %s, default setup is not enabled\n", strings.Join(workflows, ", "), repo.FullName)
+		return false, fmt.Errorf("the CodeQL workflow %s of %s still exists", strings.Join(workflows, ", "), repo.FullName)
+	}
+
+	coverage, err := repo.GetCodeqlLanguages(client)
+	if err != nil {
+		return false, err
+	}
+
+	configuration := DefaultSetupConfiguration{
+		Languages:  defaultSetupLanguages(coverage),
+		QuerySuite: QuerySuite,
+	}
+	if _, err := repo.enableDefaultSetup(client, configuration); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// migratedWorkflows returns the paths of every CodeQL workflow on the default branch, including codeql.yml.
+func (repo *Repository) migratedWorkflows(client Client) ([]string, error) {
+	workflows, err := repo.findCodeqlWorkflows(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// codeql.yml is removed even when it no longer runs the CodeQL action
+	isCodeQLEnabled, _, err := repo.doesCodeqlWorkflowExist(client)
+	if err != nil {
+		return nil, err
+	}
+	if isCodeQLEnabled && !slices.Contains(workflows, ".github/workflows/codeql.yml") {
+		workflows = append(workflows, ".github/workflows/codeql.yml")
+	}
+	return workflows, nil
+}
+
+var migrationPullRequestBody = strings.Replace(`
+	## What does this PR do?
+
+	This is an automated PR created by your security team to move GitHub Code Scanning on your repository from advanced setup to default setup. It removes the CodeQL workflow file, so your team no longer has to maintain it.
+
+	For more information on default setup, please see [here](https://docs.github.com/en/code-security/code-scanning/enabling-code-scanning/configuring-default-setup-for-code-scanning).
+
+	## What happens after I merge this PR?
+
+	Once this PR is merged, the security team will enable default setup on your repository. CodeQL will keep scanning every PR to your default branch.
+
+	If you require any further assistance, please contact the security team.
+	`, "\n\t", "\n", -1)
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestRepository_migrateToDefaultSetup(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullName   string
+		wantStatus string
+		wantPR     string
+		wantErr    bool
+	}{
+		{
+			name:       "When default setup is already enabled",
+			fullName:   "paradisisland/sheena",
+			wantStatus: StatusDefaultSetup,
+			wantPR:     "",
+			wantErr:    false,
+		},
+		{
+			name:       "When the repository has no CodeQL workflow file",
+			fullName:   "paradisisland/stohess",
+			wantStatus: StatusNoWorkflow,
+			wantPR:     "",
+			wantErr:    false,
+		},
+		{
+			name:       "When a pull request removing the CodeQL workflow file is opened",
+			fullName:   "paradisisland/shiganshima",
+			wantStatus: StatusMigrationPullRequest,
+			wantPR:     "https://github.com/paradisisland/shiganshima/pull/1348",
+			wantErr:    false,
+		},
+		{
+			name:       "When the CodeQL workflow has another filename than codeql.yml",
+			fullName:   "paradisisland/maria",
+			wantStatus: StatusMigrationPullRequest,
+			wantPR:     "https://github.com/paradisisland/maria/pull/",
+			wantErr:    false,
+		},
+		{
+			name:       "When the default setup status cannot be retrieved",
+			fullName:   "paradisisland/marley",
+			wantStatus: "",
+			wantPR:     "",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			status, createdPR, err := repo.migrateToDefaultSetup(&TestClient{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.migrateToDefaultSetup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Repository.migrateToDefaultSetup() status = %v, want %v", status, tt.wantStatus)
+			}
+			if createdPR != tt.wantPR {
+				t.Errorf("Repository.migrateToDefaultSetup() pull request = %v, want %v", createdPR, tt.wantPR)
+			}
+		})
+	}
+}
+
+func TestRepository_completeMigration(t *testing.T) {
+	tests := []struct {
+		name        string
+		fullName    string
+		state       string
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{
+			name:        "When the migration pull request is not merged yet",
+			fullName:    "paradisisland/maria",
+			state:       "open",
+			wantEnabled: false,
+			wantErr:     false,
+		},
+		{
+			name:        "When the migration pull request was merged and the workflow file deleted",
+			fullName:    "paradisisland/stohess",
+			state:       "merged",
+			wantEnabled: true,
+			wantErr:     false,
+		},
+		{
+			name:        "When default setup is already enabled",
+			fullName:    "paradisisland/sheena",
+			state:       "merged",
+			wantEnabled: false,
+			wantErr:     false,
+		},
+		{
+			name:        "When the workflow file still exists on the default branch",
+			fullName:    "paradisisland/shiganshima",
+			state:       "merged",
+			wantEnabled: false,
+			wantErr:     true,
+		},
+		{
+			name:        "When a CodeQL workflow with another filename still exists on the default branch",
+			fullName:    "paradisisland/maria",
+			state:       "merged",
+			wantEnabled: false,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			pullRequest := PullRequestStatus{Repository: tt.fullName, Branch: migrationBranch, State: tt.state}
+			enabled, err := repo.completeMigration(&TestClient{}, pullRequest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.completeMigration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if enabled != tt.wantEnabled {
+				t.Errorf("Repository.completeMigration() = %v, want %v", enabled, tt.wantEnabled)
+			}
+		})
+	}
+}
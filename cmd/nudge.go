@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var RemindAfter int
+var CloseAfter int
+var MentionCodeowners bool
+var MessageFile string
+
+const reminderMarker = "<!-- gh-add-files:reminder -->"
+
+const defaultReminderMessage = `{{ .Mentions }}This pull request to enable GitHub Code Scanning has been open for {{ .Age }} days.
+
+Please review and merge it, or take a look at the failing checks. If you require any further assistance, please contact the security team.
+`
+
+func init() {
+	nudgeCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to nudge rollout pull requests for")
+	nudgeCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	nudgeCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path to the JSON report of a previous code-scanning run")
+	nudgeCmd.MarkFlagsMutuallyExclusive("csv", "organization", "report")
+	nudgeCmd.PersistentFlags().IntVar(&RemindAfter, "remind-after", 14, "post a reminder on pull requests open for more than this number of days")
+	nudgeCmd.PersistentFlags().IntVar(&CloseAfter, "close-after", 0, "close pull requests open for more than this number of days and delete their branch, 0 never closes")
+	nudgeCmd.PersistentFlags().BoolVar(&MentionCodeowners, "mention-codeowners", false, "mention the CODEOWNERS of the workflow file in the reminder")
+	nudgeCmd.PersistentFlags().StringVarP(&MessageFile, "message", "m", "", "specify the path to a reminder message template file")
+	nudgeCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var nudgeCmd = &cobra.Command{
+	Use:   "nudge",
+	Short: "Remind owners of open rollout pull requests and close stale ones",
+	Long:  "Post a reminder on rollout pull requests that have been open too long and close the pull requests, and delete the branches, that are stale",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(ReportFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag, csv flag or report flag must be provided")
+		} else if (len(Organization) > 0 || len(CsvFile) > 0 || len(ReportFile) > 0) && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both input flags and repository names as arguments")
+		}
+
+		if RemindAfter <= 0 {
+			log.Fatalln("ERROR: The remind-after flag must be greater than 0")
+		} else if CloseAfter > 0 && CloseAfter <= RemindAfter {
+			log.Fatalln("ERROR: The close-after flag must be greater than the remind-after flag")
+		}
+
+		message := defaultReminderMessage
+		if len(MessageFile) > 0 {
+			content, err := os.ReadFile(MessageFile)
+			if err != nil {
+				log.Fatalf("ERROR: Unable to read message template file %s: %s\n", MessageFile, err)
+			}
+			message = string(content)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		var repos []Repository
+		if len(ReportFile) > 0 {
+			repos, err = loadReportRepositories(client, ReportFile)
+		} else {
+			repos, err = loadRepositories(client, Organization, CsvFile, args)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var reminded []string
+		var closed []string
+		now := time.Now()
+
+		for _, repo := range repos {
+			pullRequests, err := repo.listPullRequests(client, workflowBranch, "open")
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+
+			for _, pullRequest := range pullRequests {
+				action, err := repo.nudgePullRequest(client, pullRequest, message, now)
+				if err != nil {
+					Errors[repo.FullName] = err
+				}
+				switch action {
+				case nudgeClosed:
+					closed = append(closed, fmt.Sprintf("%s (branch %s)", pullRequest.HTMLURL, pullRequest.Head.Ref))
+				case nudgeReminded:
+					reminded = append(reminded, pullRequest.HTMLURL)
+				}
+			}
+		}
+
+		log.Printf("Number of repos processed: %d\n", len(repos))
+
+		if len(reminded) > 0 {
+			log.Printf("Pull requests reminded: %d\n", len(reminded))
+			for _, pr := range reminded {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(closed) > 0 {
+			log.Printf("Pull requests closed: %d\n", len(closed))
+			for _, pr := range closed {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+
+		log.Printf("Finished nudging pull requests! \n")
+	},
+}
+
+// nudgeClosed and nudgeReminded are what nudgePullRequest did to a pull request.
+const (
+	nudgeClosed   = "closed"
+	nudgeReminded = "reminded"
+)
+
+// nudgePullRequest closes the pull request and deletes its branch when it is stale, or posts a reminder when it has been open too long and was not reminded recently.
+// It returns what was done, which is empty when the pull request was left as it is.
+func (repo *Repository) nudgePullRequest(client Client, pullRequest PullRequest, message string, now time.Time) (string, error) {
+	age := pullRequestAge(pullRequest, now)
+
+	if CloseAfter > 0 && age >= CloseAfter {
+		log.Printf("Pull request %s has been open for %d days, closing it\n", pullRequest.HTMLURL, age)
+		if err := repo.closePullRequest(client, pullRequest.Number); err != nil {
+			return "", err
+		}
+		// the pull request is closed even when its branch is left behind
+		return nudgeClosed, repo.deleteNamedBranch(client, pullRequest.Head.Ref)
+	}
+
+	if age < RemindAfter {
+		return "", nil
+	}
+
+	remindedRecently, err := repo.hasRecentReminder(client, pullRequest.Number, now)
+	if err != nil {
+		return "", err
+	}
+	if remindedRecently {
+		log.Printf("Pull request %s was reminded less than %d days ago, skipping\n", pullRequest.HTMLURL, RemindAfter)
+		return "", nil
+	}
+
+	var mentions []string
+	if MentionCodeowners {
+		owners, err := repo.getCodeowners(client, ".github/workflows/codeql.yml")
+		if err != nil {
+			return "", err
+		}
+		for _, owner := range owners {
+			if strings.HasPrefix(owner, "@") {
+				mentions = append(mentions, owner)
+			}
+		}
+	}
+
+	body := renderReminder(message, age, mentions)
+	if _, err := repo.commentOnPullRequest(client, pullRequest.Number, body); err != nil {
+		return "", err
+	}
+	return nudgeReminded, nil
+}
+
+func (repo *Repository) hasRecentReminder(client Client, number int, now time.Time) (bool, error) {
+	comments, err := repo.listComments(client, number)
+	if err != nil {
+		return false, err
+	}
+
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, reminderMarker) && now.Sub(comment.CreatedAt) < time.Duration(RemindAfter)*24*time.Hour {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func renderReminder(message string, age int, mentions []string) string {
+	var mentionText string
+	if len(mentions) > 0 {
+		mentionText = strings.Join(mentions, " ") + " "
+	}
+
+	body := strings.ReplaceAll(message, "{{ .Mentions }}", mentionText)
+	body = strings.ReplaceAll(body, "{{ .Age }}", fmt.Sprint(age))
+	return reminderMarker + "\n" + body
+}
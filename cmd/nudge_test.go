@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepository_nudgePullRequest(t *testing.T) {
+	RemindAfter = 14
+	CloseAfter = 30
+	defer func() {
+		RemindAfter = 14
+		CloseAfter = 0
+	}()
+
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	openedDaysAgo := func(number int, days int) PullRequest {
+		pullRequest := PullRequest{Number: number, CreatedAt: now.AddDate(0, 0, -days)}
+		pullRequest.Head.Ref = workflowBranch
+		return pullRequest
+	}
+
+	tests := []struct {
+		name        string
+		fullName    string
+		pullRequest PullRequest
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "When the pull request is stale",
+			fullName:    "paradisisland/maria",
+			pullRequest: openedDaysAgo(1347, 40),
+			want:        nudgeClosed,
+			wantErr:     false,
+		},
+		{
+			name:        "When the pull request is stale and its branch cannot be deleted",
+			fullName:    "paradisisland/rose",
+			pullRequest: openedDaysAgo(12, 40),
+			want:        nudgeClosed,
+			wantErr:     true,
+		},
+		{
+			name:        "When the pull request is not open long enough for a reminder",
+			fullName:    "paradisisland/maria",
+			pullRequest: openedDaysAgo(1347, 5),
+			want:        "",
+			wantErr:     false,
+		},
+		{
+			name:        "When the pull request was reminded recently",
+			fullName:    "paradisisland/maria",
+			pullRequest: openedDaysAgo(1347, 20),
+			want:        "",
+			wantErr:     false,
+		},
+		{
+			name:        "When the pull request was reminded long ago",
+			fullName:    "paradisisland/shiganshima",
+			pullRequest: openedDaysAgo(3, 20),
+			want:        nudgeReminded,
+			wantErr:     false,
+		},
+		{
+			name:        "When the comments cannot be listed",
+			fullName:    "paradisisland/marley",
+			pullRequest: openedDaysAgo(1, 20),
+			want:        "",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			got, err := repo.nudgePullRequest(client, tt.pullRequest, defaultReminderMessage, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.nudgePullRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Repository.nudgePullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_hasRecentReminder(t *testing.T) {
+	RemindAfter = 14
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fullName string
+		number   int
+		want     bool
+		wantErr  bool
+	}{
+		{name: "When the pull request was reminded recently", fullName: "paradisisland/maria", number: 1347, want: true, wantErr: false},
+		{name: "When the pull request was only reminded long ago", fullName: "paradisisland/shiganshima", number: 3, want: false, wantErr: false},
+		{name: "When the repository is invalid", fullName: "paradisisland/marley", number: 1, want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.hasRecentReminder(client, tt.number, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.hasRecentReminder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.hasRecentReminder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderReminder(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		age      int
+		mentions []string
+		want     string
+	}{
+		{
+			name:     "When there are codeowners to mention",
+			message:  "{{ .Mentions }}Open for {{ .Age }} days.",
+			age:      21,
+			mentions: []string{"@paradisisland/scouts", "@levi"},
+			want:     reminderMarker + "\n@paradisisland/scouts @levi Open for 21 days.",
+		},
+		{
+			name:     "When there is nobody to mention",
+			message:  "{{ .Mentions }}Open for {{ .Age }} days.",
+			age:      14,
+			mentions: nil,
+			want:     reminderMarker + "\nOpen for 14 days.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderReminder(tt.message, tt.age, tt.mentions); got != tt.want {
+				t.Errorf("renderReminder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var PinActions bool
+
+// pinnedRefs caches the commit sha of each repository and ref for the run, as most repositories use the same actions.
+var pinnedRefs = map[string]string{}
+
+var commitShaPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// pinWorkflowActions rewrites the actions and reusable workflows of other owners from @tag to @sha # tag.
+// Only the lines of the references change, so comments and formatting are kept.
+func (repo *Repository) pinWorkflowActions(client Client, workflowFile []byte) ([]byte, error) {
+	root, err := parseWorkflowDocument(workflowFile)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(workflowFile), "\n")
+	var edits []textEdit
+	for _, uses := range workflowUses(root) {
+		fullName, _, ref, ok := parseReference(uses.Value)
+		if !ok || strings.EqualFold(strings.Split(fullName, "/")[0], repo.owner()) || commitShaPattern.MatchString(ref) {
+			continue
+		}
+
+		sha, err := resolveActionRef(client, fullName, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		line := lines[uses.Line-1]
+		column := uses.Column - 1
+		pinned := line[:column] + strings.Replace(line[column:], uses.Value, strings.TrimSuffix(uses.Value, ref)+sha, 1)
+		if !strings.Contains(line[column:], " #") {
+			pinned += " # " + ref
+		}
+		edits = append(edits, textEdit{start: uses.Line, end: uses.Line, lines: []string{pinned}})
+	}
+
+	return applyTextEdits(lines, edits)
+}
+
+// hasPinnedActions reports whether the workflow uses any action or reusable workflow by commit sha.
+func hasPinnedActions(workflowFile []byte) bool {
+	root, err := parseWorkflowDocument(workflowFile)
+	if err != nil {
+		return false
+	}
+	for _, uses := range workflowUses(root) {
+		if _, _, ref, ok := parseReference(uses.Value); ok && commitShaPattern.MatchString(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveActionRef returns the commit sha that the tag, branch or sha of the repository points to.
+func resolveActionRef(client Client, fullName string, ref string) (string, error) {
+	key := fullName + "@" + ref
+	if sha, ok := pinnedRefs[key]; ok {
+		return sha, nil
+	}
+
+	type Commit struct {
+		Sha string `json:"sha"`
+	}
+	var commit Commit
+	requestPath := fmt.Sprintf("repos/%s/commits/%s", fullName, ref)
+	statusCode, _, err := callApi(client, requestPath, &commit, GET)
+	if statusCode == 404 || statusCode == 422 {
+		log.Printf("ERROR: The ref %s does not exist in repository %s\n", ref, fullName)
+		return "", fmt.Errorf("unable to pin %s", key)
+	} else if err != nil {
+		log.Printf("ERROR: Unable to resolve the ref %s in repository %s\n", ref, fullName)
+		return "", err
+	}
+
+	log.Printf("Pinned %s to %s\n", key, commit.Sha)
+	pinnedRefs[key] = commit.Sha
+	return commit.Sha, nil
+}
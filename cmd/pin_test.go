@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestRepository_pinWorkflowActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "When the workflow uses actions by tag",
+			workflow: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: "github/codeql-action/init@v3" # CodeQL
+      - uses: github/codeql-action/analyze@v3
+      - uses: paradisisland/build-action@v1
+      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491
+`,
+			want: `on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4
+      - uses: "github/codeql-action/init@662472033e021d55d94146f66f6058822b0b39fd" # CodeQL
+      - uses: github/codeql-action/analyze@662472033e021d55d94146f66f6058822b0b39fd # v3
+      - uses: paradisisland/build-action@v1
+      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491
+`,
+			wantErr: false,
+		},
+		{
+			name:     "When the tag does not exist",
+			workflow: "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v9\n",
+			want:     "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinnedRefs = map[string]string{}
+			client := &TestClient{}
+			repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "main"}
+			got, err := repo.pinWorkflowActions(client, []byte(tt.workflow))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.pinWorkflowActions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Repository.pinWorkflowActions() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveActionRef(t *testing.T) {
+	pinnedRefs = map[string]string{"actions/setup-go@v5": "0c52d547c9bc32b1aa3301fd7a9cb496313a4491"}
+	defer func() { pinnedRefs = map[string]string{} }()
+
+	// a cached ref is not requested again, the mock has no response for it
+	got, err := resolveActionRef(&TestClient{}, "actions/setup-go", "v5")
+	if err != nil || got != "0c52d547c9bc32b1aa3301fd7a9cb496313a4491" {
+		t.Errorf("resolveActionRef() = %v, %v, want the cached sha", got, err)
+	}
+}
+
+func Test_hasPinnedActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     bool
+	}{
+		{
+			name:     "When an action is pinned to a commit sha",
+			workflow: "on: push\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4\n",
+			want:     true,
+		},
+		{
+			name:     "When the actions are used by tag",
+			workflow: "on: push\njobs:\n  analyze:\n    steps:\n      - uses: actions/checkout@v4\n",
+			want:     false,
+		},
+		{
+			name:     "When the workflow is not valid YAML",
+			workflow: "on: [ push\n",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasPinnedActions([]byte(tt.workflow)); got != tt.want {
+				t.Errorf("hasPinnedActions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
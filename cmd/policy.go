@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModeAuto picks default or advanced setup for every repository from the policy.
+const ModeAuto = "auto"
+
+var PolicyFile string
+
+// Policy decides which repositories get default setup and which get advanced setup.
+type Policy struct {
+	// DefaultSetupLanguages are the languages that can be analyzed without a manual build
+	DefaultSetupLanguages []string `yaml:"default-setup-languages"`
+	// AdvancedSetupRepositories always get advanced setup, e.g. because they need a custom configuration
+	AdvancedSetupRepositories []string `yaml:"advanced-setup-repositories"`
+	// DefaultSetupRepositories always get default setup
+	DefaultSetupRepositories []string `yaml:"default-setup-repositories"`
+}
+
+// defaultPolicy uses default setup for interpreted languages and the compiled languages CodeQL can analyze without a build.
+var defaultPolicy = Policy{
+	DefaultSetupLanguages: []string{"JavaScript", "Python", "Ruby", "Java", "Kotlin", "Csharp", "Go"},
+}
+
+func loadPolicy(PolicyFile string) (Policy, error) {
+	if len(PolicyFile) <= 0 {
+		return defaultPolicy, nil
+	}
+
+	content, err := os.ReadFile(PolicyFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to read policy file %s\n", PolicyFile)
+		return Policy{}, err
+	}
+
+	policy := Policy{}
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		log.Printf("ERROR: Unable to parse policy file %s\n", PolicyFile)
+		return Policy{}, err
+	}
+	if policy.DefaultSetupLanguages == nil {
+		policy.DefaultSetupLanguages = defaultPolicy.DefaultSetupLanguages
+	}
+	return policy, nil
+}
+
+// chooseMode returns the setup mode for the repository based on its detected languages.
+func (policy Policy) chooseMode(FullName string, coverage []string) string {
+	if containsFold(policy.AdvancedSetupRepositories, FullName) {
+		return ModeAdvanced
+	}
+	if containsFold(policy.DefaultSetupRepositories, FullName) {
+		return ModeDefault
+	}
+
+	for _, language := range coverage {
+		if !containsFold(policy.DefaultSetupLanguages, language) {
+			return ModeAdvanced
+		}
+	}
+	return ModeDefault
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
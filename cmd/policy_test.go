@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPolicy_chooseMode(t *testing.T) {
+	policy := Policy{
+		DefaultSetupLanguages:     []string{"JavaScript", "python"},
+		AdvancedSetupRepositories: []string{"paradisisland/rose"},
+		DefaultSetupRepositories:  []string{"paradisisland/sheena"},
+	}
+
+	tests := []struct {
+		name     string
+		fullName string
+		coverage []string
+		want     string
+	}{
+		{
+			name:     "When all languages can use default setup",
+			fullName: "paradisisland/maria",
+			coverage: []string{"JavaScript", "Python"},
+			want:     ModeDefault,
+		},
+		{
+			name:     "When a language needs a manual build",
+			fullName: "paradisisland/maria",
+			coverage: []string{"JavaScript", "Cpp"},
+			want:     ModeAdvanced,
+		},
+		{
+			name:     "When the repository must use advanced setup",
+			fullName: "paradisisland/rose",
+			coverage: []string{"Python"},
+			want:     ModeAdvanced,
+		},
+		{
+			name:     "When the repository must use default setup",
+			fullName: "paradisisland/sheena",
+			coverage: []string{"Swift"},
+			want:     ModeDefault,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.chooseMode(tt.fullName, tt.coverage); got != tt.want {
+				t.Errorf("Policy.chooseMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy.yml")
+	if err := os.WriteFile(policyFile, []byte("advanced-setup-repositories:\n  - paradisisland/rose\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.yml")
+	if err := os.WriteFile(invalidFile, []byte("advanced-setup-repositories: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		policyFile string
+		want       Policy
+		wantErr    bool
+	}{
+		{
+			name:       "When no policy file is provided",
+			policyFile: "",
+			want:       defaultPolicy,
+			wantErr:    false,
+		},
+		{
+			name:       "When the policy file only lists repositories",
+			policyFile: policyFile,
+			want: Policy{
+				DefaultSetupLanguages:     defaultPolicy.DefaultSetupLanguages,
+				AdvancedSetupRepositories: []string{"paradisisland/rose"},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "When the policy file is invalid",
+			policyFile: invalidFile,
+			want:       Policy{},
+			wantErr:    true,
+		},
+		{
+			name:       "When the policy file does not exist",
+			policyFile: filepath.Join(dir, "missing.yml"),
+			want:       Policy{},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadPolicy(tt.policyFile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadPolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
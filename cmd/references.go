@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+var SkipReferenceCheck bool
+
+// ReferencedRepository holds the settings of a repository that a workflow uses an action or reusable workflow from.
+type ReferencedRepository struct {
+	FullName    string `json:"full_name"`
+	Visibility  string `json:"visibility"`
+	AccessLevel string `json:"-"`
+}
+
+// referencedRepositories and referenceChecks cache the lookups of a run, as most repositories render the same references.
+var referencedRepositories = map[string]*ReferencedRepository{}
+var referenceChecks = map[string]error{}
+
+// parseReference splits an action or reusable workflow reference into the repository, the path in it and the ref.
+// Local paths and docker images are not references to another repository.
+func parseReference(uses string) (string, string, string, bool) {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return "", "", "", false
+	}
+	target, ref, found := strings.Cut(uses, "@")
+	parts := strings.SplitN(target, "/", 3)
+	if !found || len(parts) < 2 {
+		return "", "", "", false
+	}
+	path := ""
+	if len(parts) == 3 {
+		path = parts[2]
+	}
+	return parts[0] + "/" + parts[1], path, ref, true
+}
+
+// checkWorkflowReferencesExist checks that every action and reusable workflow the workflow uses from another repository exists.
+func checkWorkflowReferencesExist(client Client, workflowFile []byte) error {
+	root, err := parseWorkflowDocument(workflowFile)
+	if err != nil {
+		return err
+	}
+
+	for _, uses := range workflowUses(root) {
+		fullName, path, ref, ok := parseReference(uses.Value)
+		if !ok {
+			continue
+		}
+		if err := checkReferenceExists(client, uses.Value, fullName, path, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyWorkflowReferences checks that every action and reusable workflow the workflow uses exists and that the repository is allowed to use it.
+func (repo *Repository) verifyWorkflowReferences(client Client, workflowFile []byte) error {
+	root, err := parseWorkflowDocument(workflowFile)
+	if err != nil {
+		return err
+	}
+
+	for _, uses := range workflowUses(root) {
+		fullName, path, ref, ok := parseReference(uses.Value)
+		if !ok || strings.EqualFold(fullName, repo.FullName) {
+			continue
+		}
+
+		if err := checkReferenceExists(client, uses.Value, fullName, path, ref); err != nil {
+			return err
+		}
+
+		referenced, err := getReferencedRepository(client, fullName)
+		if err != nil {
+			return err
+		}
+		if !repo.canUseReference(referenced) {
+			log.Printf("ERROR: The repository %s cannot use %s, the Actions access of %s is %s\n", repo.FullName, uses.Value, fullName, referenced.AccessLevel)
+			return fmt.Errorf("%s is not accessible from %s", uses.Value, repo.FullName)
+		}
+	}
+	return nil
+}
+
+// checkReferenceExists checks that the path exists at the ref, or the ref itself when the action is at the root of the repository.
+func checkReferenceExists(client Client, uses string, fullName string, path string, ref string) error {
+	if err, ok := referenceChecks[uses]; ok {
+		return err
+	}
+
+	var response interface{}
+	requestPath := fmt.Sprintf("repos/%s/commits/%s", fullName, ref)
+	if len(path) > 0 {
+		requestPath = fmt.Sprintf("repos/%s/contents/%s?ref=%s", fullName, path, ref)
+	}
+	statusCode, _, err := callApi(client, requestPath, &response, GET)
+	if statusCode == 404 || statusCode == 422 {
+		log.Printf("ERROR: %s does not exist or is not visible to this token\n", uses)
+		err = fmt.Errorf("%s does not exist", uses)
+	} else if err != nil {
+		log.Printf("ERROR: Unable to check %s\n", uses)
+	}
+
+	referenceChecks[uses] = err
+	return err
+}
+
+// getReferencedRepository reads the visibility of the repository and, unless it is public, who its Actions access is shared with.
+func getReferencedRepository(client Client, fullName string) (*ReferencedRepository, error) {
+	if referenced, ok := referencedRepositories[fullName]; ok {
+		return referenced, nil
+	}
+
+	referenced := &ReferencedRepository{}
+	requestPath := fmt.Sprintf("repos/%s", fullName)
+	if _, _, err := callApi(client, requestPath, referenced, GET); err != nil {
+		log.Printf("ERROR: Unable to get repository %s\n", fullName)
+		return nil, err
+	}
+	referenced.FullName = fullName
+
+	if referenced.Visibility != "public" {
+		type Access struct {
+			AccessLevel string `json:"access_level"`
+		}
+		var access Access
+		requestPath := fmt.Sprintf("repos/%s/actions/permissions/access", fullName)
+		if _, _, err := callApi(client, requestPath, &access, GET); err != nil {
+			log.Printf("ERROR: Unable to get the Actions access of repository %s\n", fullName)
+			return nil, err
+		}
+		referenced.AccessLevel = access.AccessLevel
+	}
+
+	referencedRepositories[fullName] = referenced
+	return referenced, nil
+}
+
+// canUseReference reports whether the Actions access of the referenced repository includes the repository.
+// Enterprise membership cannot be read with the repository APIs, so enterprise access to another owner is assumed to include it.
+func (repo *Repository) canUseReference(referenced *ReferencedRepository) bool {
+	if referenced.Visibility == "public" {
+		return true
+	}
+	sameOwner := strings.EqualFold(strings.Split(referenced.FullName, "/")[0], repo.owner())
+	switch referenced.AccessLevel {
+	case "user", "organization":
+		return sameOwner
+	case "enterprise":
+		if !sameOwner {
+			log.Printf("WARN: %s shares its Actions with its enterprise, make sure %s is in the same enterprise\n", referenced.FullName, repo.FullName)
+		}
+		return true
+	}
+	return false
+}
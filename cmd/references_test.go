@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_parseReference(t *testing.T) {
+	tests := []struct {
+		name         string
+		uses         string
+		wantFullName string
+		wantPath     string
+		wantRef      string
+		wantOk       bool
+	}{
+		{name: "When the action is at the root of the repository", uses: "actions/checkout@v4", wantFullName: "actions/checkout", wantPath: "", wantRef: "v4", wantOk: true},
+		{name: "When the action is in a directory", uses: "github/codeql-action/init@v3", wantFullName: "github/codeql-action", wantPath: "init", wantRef: "v3", wantOk: true},
+		{name: "When the reference is a reusable workflow", uses: "my-org/security/.github/workflows/codeql.yml@main", wantFullName: "my-org/security", wantPath: ".github/workflows/codeql.yml", wantRef: "main", wantOk: true},
+		{name: "When the action is local", uses: "./.github/actions/build", wantOk: false},
+		{name: "When the action is a docker image", uses: "docker://alpine:3.19", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fullName, path, ref, ok := parseReference(tt.uses)
+			if fullName != tt.wantFullName || path != tt.wantPath || ref != tt.wantRef || ok != tt.wantOk {
+				t.Errorf("parseReference() = %v, %v, %v, %v, want %v, %v, %v, %v", fullName, path, ref, ok, tt.wantFullName, tt.wantPath, tt.wantRef, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRepository_verifyWorkflowReferences(t *testing.T) {
+	centralWorkflow := "on: push\njobs:\n  code_analysis:\n    uses: advanced-security-demo/central-repo-test/.github/workflows/code_analysis.yml@main\n"
+	tests := []struct {
+		name     string
+		fullName string
+		workflow string
+		wantErr  bool
+	}{
+		{
+			name:     "When the central workflow is shared with the organization of the repository",
+			fullName: "advanced-security-demo/app",
+			workflow: centralWorkflow,
+			wantErr:  false,
+		},
+		{
+			name:     "When the central workflow is not shared with the organization of the repository",
+			fullName: "paradisisland/maria",
+			workflow: centralWorkflow,
+			wantErr:  true,
+		},
+		{
+			name:     "When the central workflow does not exist",
+			fullName: "advanced-security-demo/app",
+			workflow: "on: push\njobs:\n  code_analysis:\n    uses: advanced-security-demo/central-repo-test/.github/workflows/code_analysis.yaml@main\n",
+			wantErr:  true,
+		},
+		{
+			name:     "When the workflow uses public and local actions",
+			fullName: "paradisisland/maria",
+			workflow: "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - uses: ./.github/actions/build\n",
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referencedRepositories = map[string]*ReferencedRepository{}
+			referenceChecks = map[string]error{}
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			err := repo.verifyWorkflowReferences(client, []byte(tt.workflow))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.verifyWorkflowReferences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_checkWorkflowReferencesExist(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		wantErr  bool
+	}{
+		{
+			name:     "When the central workflow exists",
+			workflow: "on: push\njobs:\n  code_analysis:\n    uses: advanced-security-demo/central-repo-test/.github/workflows/code_analysis.yml@main\n",
+			wantErr:  false,
+		},
+		{
+			name:     "When the central workflow does not exist",
+			workflow: "on: push\njobs:\n  code_analysis:\n    uses: advanced-security-demo/central-repo-test/.github/workflows/code_analysis.yaml@main\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referencedRepositories = map[string]*ReferencedRepository{}
+			referenceChecks = map[string]error{}
+			client := &TestClient{}
+			if err := checkWorkflowReferencesExist(client, []byte(tt.workflow)); (err != nil) != tt.wantErr {
+				t.Errorf("checkWorkflowReferencesExist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	refreshCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to refresh rollout pull requests for")
+	refreshCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	refreshCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path to the JSON report of a previous code-scanning run")
+	refreshCmd.MarkFlagsMutuallyExclusive("csv", "organization", "report")
+	refreshCmd.PersistentFlags().StringVarP(&WorkflowFile, "workflow", "w", "", "specify the path to the code scanning workflow file to recommit when it has changed")
+	refreshCmd.PersistentFlags().StringVarP(&TemplateFile, "template", "t", "", "specify the path to the code scanning workflow template file to recommit when it has changed")
+	refreshCmd.MarkFlagsMutuallyExclusive("workflow", "template")
+	refreshCmd.PersistentFlags().StringVar(&BuildConfigFile, "build-config", "", "specify the path to the YAML file of manual build commands that code-scanning used")
+	refreshCmd.PersistentFlags().BoolVar(&PinActions, "pin-actions", false, "pin the actions and reusable workflows of other owners in the workflow file, as code-scanning did")
+	refreshCmd.PersistentFlags().BoolVar(&MergeWorkflow, "merge", false, "merge the workflow file into the existing codeql.yml of the default branch, as code-scanning did")
+	refreshCmd.PersistentFlags().BoolVar(&SkipReferenceCheck, "skip-reference-check", false, "do not check that the actions and reusable workflows the workflow file uses exist and are accessible from each repository")
+	refreshCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Bring open rollout pull requests up to date",
+	Long:  "Update the branch of open rollout pull requests with their base branch and recommit the workflow file when the template has changed",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(ReportFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag, csv flag or report flag must be provided")
+		} else if (len(Organization) > 0 || len(CsvFile) > 0 || len(ReportFile) > 0) && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both input flags and repository names as arguments")
+		}
+
+		if err := loadBuildCommands(BuildConfigFile, CsvFile); err != nil {
+			log.Fatalln(err)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		var repos []Repository
+		if len(ReportFile) > 0 {
+			repos, err = loadReportRepositories(client, ReportFile)
+		} else {
+			repos, err = loadRepositories(client, Organization, CsvFile, args)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var updated []string
+		var recommitted []string
+		var teamEdited []string
+		var upToDate []string
+
+		for _, repo := range repos {
+			pullRequests, err := repo.listPullRequests(client, workflowBranch, "open")
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+
+			for _, pullRequest := range pullRequests {
+				expectedHeadSha := pullRequest.Head.Sha
+
+				if len(TemplateFile) > 0 || len(WorkflowFile) > 0 {
+					outcome, err := repo.recommitWorkflowFile(client, pullRequest)
+					if err != nil {
+						Errors[repo.FullName] = err
+						continue
+					}
+					switch outcome {
+					case TemplateRecommitted:
+						recommitted = append(recommitted, pullRequest.HTMLURL)
+						// the recommit moved the head of the branch
+						expectedHeadSha = ""
+					case TemplateTeamEdited:
+						teamEdited = append(teamEdited, pullRequest.HTMLURL)
+					}
+				}
+
+				isUpdated, err := repo.updatePullRequestBranch(client, pullRequest.Number, expectedHeadSha)
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+				if isUpdated {
+					updated = append(updated, pullRequest.HTMLURL)
+				} else {
+					upToDate = append(upToDate, pullRequest.HTMLURL)
+				}
+			}
+		}
+
+		log.Printf("Number of repos processed: %d\n", len(repos))
+
+		if len(recommitted) > 0 {
+			log.Printf("Pull requests with a recommitted workflow file: %d\n", len(recommitted))
+			for _, pr := range recommitted {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(teamEdited) > 0 {
+			log.Printf("Pull requests with team changes, the workflow file was not recommitted: %d\n", len(teamEdited))
+			for _, pr := range teamEdited {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(updated) > 0 {
+			log.Printf("Pull requests updated with their base branch: %d\n", len(updated))
+			for _, pr := range updated {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(upToDate) > 0 {
+			log.Printf("Pull requests already up to date: %d\n", len(upToDate))
+			for _, pr := range upToDate {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+
+		log.Printf("Finished refreshing pull requests! \n")
+	},
+}
+
+// templateHashPattern matches the hash of the rendered template that raisePullRequest records in the body of the pull request.
+var templateHashPattern = regexp.MustCompile(`<!-- gh-add-files:template-sha256:([0-9a-f]*) -->`)
+
+// The outcomes of recommitWorkflowFile.
+const (
+	TemplateUnchanged   = "unchanged"
+	TemplateRecommitted = "recommitted"
+	TemplateTeamEdited  = "edited by the team"
+)
+
+func templateHash(rendered []byte) string {
+	sum := sha256.Sum256(rendered)
+	return hex.EncodeToString(sum[:])
+}
+
+// setTemplateHash records the hash in the body of the pull request, replacing the hash that is already there.
+func setTemplateHash(body string, hash string) string {
+	marker := fmt.Sprintf("<!-- gh-add-files:template-sha256:%s -->", hash)
+	if templateHashPattern.MatchString(body) {
+		return templateHashPattern.ReplaceAllLiteralString(body, marker)
+	}
+	return body + "\n" + marker + "\n"
+}
+
+// recommitWorkflowFile commits the workflow file to the pull request branch when the template has changed since the pull request was opened.
+// The file goes through the same build commands, pinning and merge as code-scanning.
+// Branches with commits that add-files did not make are left as they are, so the changes of the team are kept.
+func (repo *Repository) recommitWorkflowFile(client Client, pullRequest PullRequest) (string, error) {
+	rendered, err := repo.renderCodeqlWorkflowFile()
+	if err != nil {
+		return "", err
+	}
+	hash := templateHash(rendered)
+	if recorded := templateHashPattern.FindStringSubmatch(pullRequest.Body); recorded != nil && recorded[1] == hash {
+		log.Printf("The template of pull request %s has not changed\n", pullRequest.HTMLURL)
+		return TemplateUnchanged, nil
+	}
+
+	comparison, err := repo.compareBranch(client, pullRequest.Head.Ref)
+	if err != nil {
+		return "", err
+	}
+	for _, commit := range comparison.Commits {
+		// merges of the base branch are made by update-branch
+		if len(commit.Parents) > 1 {
+			continue
+		}
+		if !repo.isOwnedCommit(commit, "") {
+			author := commit.Commit.Author
+			log.Printf("WARN: The branch of pull request %s has commit %s by %s <%s>, the workflow file is not recommitted\n", pullRequest.HTMLURL, commit.Sha, author.Name, author.Email)
+			return TemplateTeamEdited, nil
+		}
+	}
+
+	languages, err := repo.GetCodeqlLanguages(client)
+	if err != nil {
+		return "", err
+	}
+	workflowFile, _, err := repo.prepareCodeqlWorkflowFile(client, rendered, languages, true)
+	if err != nil {
+		return "", err
+	}
+
+	outcome := TemplateUnchanged
+	current, sha, err := repo.getFileContent(client, ".github/workflows/codeql.yml", pullRequest.Head.Ref)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(current, workflowFile) {
+		log.Printf("The template of pull request %s has changed, recommitting the workflow file\n", pullRequest.HTMLURL)
+		if _, err := repo.commitWorkflowFile(client, workflowFile, sha); err != nil {
+			return "", err
+		}
+		outcome = TemplateRecommitted
+	}
+
+	if err := repo.updatePullRequestBody(client, pullRequest.Number, setTemplateHash(pullRequest.Body, hash)); err != nil {
+		return outcome, err
+	}
+	return outcome, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRepository_recommitWorkflowFile(t *testing.T) {
+	TemplateFile = "../examples/codeql-template.yml"
+	SkipReferenceCheck = true
+	defer func() {
+		TemplateFile = ""
+		SkipReferenceCheck = false
+	}()
+
+	template, err := os.ReadFile(TemplateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "main"}
+	currentHash := templateHash([]byte(repo.renderTemplate(string(template), nil)))
+	previousHash := templateHash([]byte("name: CodeQL\n"))
+
+	tests := []struct {
+		name     string
+		fullName string
+		number   int
+		body     string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "When the template has not changed since the pull request was opened",
+			fullName: "paradisisland/maria",
+			number:   1347,
+			body:     setTemplateHash("Automated PR", currentHash),
+			want:     TemplateUnchanged,
+			wantErr:  false,
+		},
+		{
+			name:     "When the template has changed since the pull request was opened",
+			fullName: "paradisisland/shiganshima",
+			number:   3,
+			body:     setTemplateHash("Automated PR", previousHash),
+			want:     TemplateRecommitted,
+			wantErr:  false,
+		},
+		{
+			name:     "When the team has changed the branch of the pull request",
+			fullName: "paradisisland/sheena",
+			number:   7,
+			body:     setTemplateHash("Automated PR", previousHash),
+			want:     TemplateTeamEdited,
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName, DefaultBranch: "main"}
+			pullRequest := PullRequest{Number: tt.number, Body: tt.body}
+			pullRequest.Head.Ref = workflowBranch
+			got, err := repo.recommitWorkflowFile(client, pullRequest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.recommitWorkflowFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Repository.recommitWorkflowFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setTemplateHash(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		hash string
+		want string
+	}{
+		{name: "When the body has no hash", body: "Automated PR\n", hash: "abc123", want: "Automated PR\n\n<!-- gh-add-files:template-sha256:abc123 -->\n"},
+		{name: "When the body has a hash", body: "Automated PR\n<!-- gh-add-files:template-sha256:0f0f -->\n", hash: "abc123", want: "Automated PR\n<!-- gh-add-files:template-sha256:abc123 -->\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setTemplateHash(tt.body, tt.hash); got != tt.want {
+				t.Errorf("setTemplateHash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
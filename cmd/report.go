@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+)
+
+// RunReport records what a code-scanning run did to each repository so that
+// later commands can pick up the repositories it touched.
+type RunReport struct {
+	StartedAt    time.Time           `json:"started_at"`
+	FinishedAt   time.Time           `json:"finished_at"`
+	Repositories []*RepositoryReport `json:"repositories"`
+}
+
+// RepositoryReport is the outcome of a run for a single repository.
+type RepositoryReport struct {
+	FullName             string `json:"full_name"`
+	Status               string `json:"status"`
+	Branch               string `json:"branch,omitempty"`
+	PullRequest          string `json:"pull_request,omitempty"`
+	DefaultSetupDisabled bool   `json:"default_setup_disabled,omitempty"`
+	// PreviousDefaultSetup is the default setup configuration before it was disabled
+	PreviousDefaultSetup *DefaultSetupConfiguration `json:"previous_default_setup,omitempty"`
+	// DefaultSetupRun is the conclusion of the default setup configuration run
+	DefaultSetupRun string `json:"default_setup_run,omitempty"`
+	// AdvancedSecurityEnabled is set when the run enabled Advanced Security with --enable-ghas
+	AdvancedSecurityEnabled bool   `json:"advanced_security_enabled,omitempty"`
+	CommitterSeatImpact     string `json:"committer_seat_impact,omitempty"`
+	Error                   string `json:"error,omitempty"`
+}
+
+const (
+	StatusPullRequest   = "pull-request"
+	StatusNoLanguage    = "no-language"
+	StatusDefaultSetup  = "default-setup"
+	StatusAdvancedSetup = "advanced-setup"
+	StatusNoWorkflow    = "no-workflow"
+	StatusUpToDate      = "up-to-date"
+	StatusDryRun        = "dry-run"
+	StatusError         = "error"
+)
+
+func newRunReport() *RunReport {
+	return &RunReport{StartedAt: time.Now().UTC()}
+}
+
+// record returns the entry for the repository, creating it if needed.
+func (report *RunReport) record(FullName string) *RepositoryReport {
+	for _, entry := range report.Repositories {
+		if entry.FullName == FullName {
+			return entry
+		}
+	}
+	entry := &RepositoryReport{FullName: FullName}
+	report.Repositories = append(report.Repositories, entry)
+	return entry
+}
+
+func (report *RunReport) recordErrors(errs map[string]error) {
+	for repository, err := range errs {
+		entry := report.record(repository)
+		entry.Status = StatusError
+		entry.Error = err.Error()
+	}
+}
+
+func (report *RunReport) write(ReportFile string) error {
+	report.FinishedAt = time.Now().UTC()
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Printf("ERROR: Unable to marshal run report\n")
+		return err
+	}
+
+	err = os.WriteFile(ReportFile, content, 0644)
+	if err != nil {
+		log.Printf("ERROR: Unable to write run report to %s\n", ReportFile)
+		return err
+	}
+
+	log.Printf("Run report written to %s\n", ReportFile)
+	return nil
+}
+
+func readRunReport(ReportFile string) (*RunReport, error) {
+	content, err := os.ReadFile(ReportFile)
+	if err != nil {
+		log.Printf("ERROR: Unable to read run report %s\n", ReportFile)
+		return nil, err
+	}
+
+	var report RunReport
+	err = json.Unmarshal(content, &report)
+	if err != nil {
+		log.Printf("ERROR: Unable to parse run report %s\n", ReportFile)
+		return nil, err
+	}
+	return &report, nil
+}
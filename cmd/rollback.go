@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rollbackCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path to the JSON report of the code-scanning run to roll back")
+	rollbackCmd.MarkPersistentFlagRequired("report")
+	rollbackCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Reverse the changes of a code-scanning run",
+	Long:  "Close the pull requests and delete the branches a code-scanning run created, and re-enable default setup where it was disabled",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		logFile, err := setupLogging(LogFile, os.Stdout)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		report, err := readRunReport(ReportFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		var closed []string
+		var merged []string
+		var restored []string
+
+		for _, entry := range report.Repositories {
+			if len(entry.PullRequest) <= 0 && !entry.DefaultSetupDisabled {
+				continue
+			}
+
+			repo, err := getRepo(entry.FullName, client)
+			if err != nil {
+				Errors[entry.FullName] = err
+				continue
+			}
+
+			prClosed, prMerged, isRestored, err := repo.rollbackRepository(client, entry)
+			closed = append(closed, prClosed...)
+			merged = append(merged, prMerged...)
+			if isRestored {
+				restored = append(restored, repo.FullName)
+			}
+			if err != nil {
+				Errors[repo.FullName] = err
+			}
+		}
+
+		if len(closed) > 0 {
+			log.Printf("Pull requests closed: %d\n", len(closed))
+			for _, pr := range closed {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(merged) > 0 {
+			log.Printf("Pull requests already merged that need to be reverted manually: %d\n", len(merged))
+			for _, pr := range merged {
+				log.Printf("PR URL: %s\n", pr)
+			}
+		}
+
+		if len(restored) > 0 {
+			log.Printf("Repositories with default setup re-enabled: %d\n", len(restored))
+			for _, repo := range restored {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+
+		log.Printf("Finished rollback! \n")
+	},
+}
+
+// rollbackRepository closes the pull request of the report entry, deletes its branch and re-enables default setup if the run disabled it.
+// Default setup is restored even when the pull request cannot be cleaned up, so the code scanning state does not depend on it.
+func (repo *Repository) rollbackRepository(client Client, entry *RepositoryReport) ([]string, []string, bool, error) {
+	var closed []string
+	var merged []string
+	var cleanupErr error
+
+	if len(entry.PullRequest) > 0 {
+		closed, merged, cleanupErr = repo.rollbackPullRequest(client, entry)
+	}
+
+	if !entry.DefaultSetupDisabled {
+		return closed, merged, false, cleanupErr
+	}
+
+	configuration := DefaultSetupConfiguration{}
+	if entry.PreviousDefaultSetup != nil {
+		configuration = *entry.PreviousDefaultSetup
+	} else {
+		log.Printf("WARN: The report has no previous default setup configuration for %s, re-enabling default setup with the default configuration\n", repo.FullName)
+	}
+
+	if _, err := repo.enableDefaultSetup(client, configuration); err != nil {
+		return closed, merged, false, errors.Join(cleanupErr, err)
+	}
+	return closed, merged, true, cleanupErr
+}
+
+// rollbackPullRequest closes the pull request of the report entry and deletes its branch, unless it has already been merged.
+func (repo *Repository) rollbackPullRequest(client Client, entry *RepositoryReport) ([]string, []string, error) {
+	var closed []string
+	var merged []string
+
+	pullRequests, err := repo.listPullRequests(client, entry.Branch, "all")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, pullRequest := range pullRequests {
+		if pullRequest.HTMLURL != entry.PullRequest {
+			continue
+		}
+
+		if pullRequest.MergedAt != nil {
+			log.Printf("WARN: Pull request %s has already been merged, the workflow file must be removed from %s\n", pullRequest.HTMLURL, repo.FullName)
+			merged = append(merged, pullRequest.HTMLURL)
+			continue
+		}
+
+		if pullRequest.State == "open" {
+			if err := repo.closePullRequest(client, pullRequest.Number); err != nil {
+				return closed, merged, err
+			}
+		}
+		if err := repo.deleteNamedBranch(client, entry.Branch); err != nil {
+			return closed, merged, err
+		}
+		closed = append(closed, pullRequest.HTMLURL)
+	}
+	return closed, merged, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepository_rollbackRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		entry        *RepositoryReport
+		wantClosed   []string
+		wantRestored bool
+		wantErr      bool
+	}{
+		{
+			name:         "When the pull request is open",
+			entry:        &RepositoryReport{FullName: "paradisisland/maria", Branch: workflowBranch, PullRequest: "https://github.com/paradisisland/maria/pull/1347"},
+			wantClosed:   []string{"https://github.com/paradisisland/maria/pull/1347"},
+			wantRestored: false,
+			wantErr:      false,
+		},
+		{
+			name:         "When the pull request is closed and default setup was disabled",
+			entry:        &RepositoryReport{FullName: "paradisisland/maria", Branch: workflowBranch, PullRequest: "https://github.com/paradisisland/maria/pull/1347", DefaultSetupDisabled: true, PreviousDefaultSetup: &DefaultSetupConfiguration{QuerySuite: "extended"}},
+			wantClosed:   []string{"https://github.com/paradisisland/maria/pull/1347"},
+			wantRestored: true,
+			wantErr:      false,
+		},
+		{
+			name:         "When the pull request is a migration pull request",
+			entry:        &RepositoryReport{FullName: "paradisisland/sheena", Status: StatusMigrationPullRequest, Branch: migrationBranch, PullRequest: "https://github.com/paradisisland/sheena/pull/21"},
+			wantClosed:   []string{"https://github.com/paradisisland/sheena/pull/21"},
+			wantRestored: false,
+			wantErr:      false,
+		},
+		{
+			name:         "When listing the pull requests fails",
+			entry:        &RepositoryReport{FullName: "paradisisland/shiganshima", Branch: workflowBranch, PullRequest: "https://github.com/paradisisland/shiganshima/pull/1348", DefaultSetupDisabled: true},
+			wantClosed:   nil,
+			wantRestored: true,
+			wantErr:      true,
+		},
+		{
+			name:         "When default setup cannot be re-enabled",
+			entry:        &RepositoryReport{FullName: "paradisisland/marley", DefaultSetupDisabled: true},
+			wantClosed:   nil,
+			wantRestored: false,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.entry.FullName, DefaultBranch: "main"}
+			closed, _, restored, err := repo.rollbackRepository(&TestClient{}, tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.rollbackRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(closed, tt.wantClosed) {
+				t.Errorf("Repository.rollbackRepository() closed = %v, want %v", closed, tt.wantClosed)
+			}
+			if restored != tt.wantRestored {
+				t.Errorf("Repository.rollbackRepository() restored = %v, want %v", restored, tt.wantRestored)
+			}
+		})
+	}
+}
@@ -10,6 +10,16 @@ import (
 func init() {
 	rootCmd.AddCommand(codeScanningCmd)
 	rootCmd.AddCommand(deleteBranchCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(diagnoseCmd)
+	rootCmd.AddCommand(nudgeCmd)
+	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(securityConfigCmd)
+	rootCmd.AddCommand(dependabotCmd)
+	rootCmd.AddCommand(dependencyReviewCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(inventoryCmd)
 }
 
 var rootCmd = &cobra.Command{
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var ConfigurationName string
+var ListConfigurations bool
+var ConfigurationPollInterval time.Duration
+var ConfigurationPollTimeout time.Duration
+
+// CodeSecurityConfiguration is an organization code security configuration.
+type CodeSecurityConfiguration struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	TargetType  string `json:"target_type"`
+	Description string `json:"description"`
+	Enforcement string `json:"enforcement"`
+}
+
+// ConfigurationStatus is the code security configuration attached to a single repository.
+type ConfigurationStatus struct {
+	Repository    string `json:"repository"`
+	Configuration string `json:"configuration,omitempty"`
+	Status        string `json:"status"`
+	Enforcement   string `json:"enforcement,omitempty"`
+}
+
+func init() {
+	securityConfigCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to attach the code security configuration in")
+	securityConfigCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	securityConfigCmd.MarkFlagsMutuallyExclusive("csv", "organization")
+	securityConfigCmd.PersistentFlags().StringVarP(&ConfigurationName, "name", "n", "", "specify the name of the code security configuration to attach")
+	securityConfigCmd.PersistentFlags().BoolVar(&ListConfigurations, "list", false, "list the code security configurations of the organization")
+	securityConfigCmd.MarkFlagsMutuallyExclusive("name", "list")
+	securityConfigCmd.PersistentFlags().DurationVar(&ConfigurationPollInterval, "poll-interval", 5*time.Second, "specify how often the status of an attaching configuration is checked")
+	securityConfigCmd.PersistentFlags().DurationVar(&ConfigurationPollTimeout, "poll-timeout", 2*time.Minute, "specify how long to wait for attached configurations to be applied")
+	securityConfigCmd.PersistentFlags().StringVarP(&OutputFormat, "format", "F", "table", "specify the output format: table or json")
+	securityConfigCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var securityConfigCmd = &cobra.Command{
+	Use:   "security-config",
+	Short: "Attach organization code security configurations",
+	Long:  "List the code security configurations of an organization, attach a configuration to each repo in organisation, csv file or argument list, and report the configuration status of each repo",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		// logs go to stderr so the table or JSON output can be piped
+		logFile, err := setupLogging(LogFile, os.Stderr)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		if ListConfigurations && len(Organization) <= 0 {
+			log.Fatalln("ERROR: The organization flag must be provided to list code security configurations")
+		} else if !ListConfigurations && len(Organization) <= 0 && len(CsvFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag or csv flag must be provided")
+		} else if len(Organization) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both organization flag and repository names as arguments")
+		} else if len(CsvFile) > 0 && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both csv flag and repository names as arguments")
+		}
+
+		if OutputFormat != "table" && OutputFormat != "json" {
+			log.Fatalf("ERROR: Unknown output format %s, must be table or json\n", OutputFormat)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		if ListConfigurations {
+			configurations, err := listCodeSecurityConfigurations(client, Organization)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if OutputFormat == "json" {
+				err = printJSON(os.Stdout, configurations)
+			} else {
+				var rows [][]string
+				for _, configuration := range configurations {
+					rows = append(rows, []string{fmt.Sprint(configuration.ID), configuration.Name, configuration.TargetType, configuration.Enforcement, configuration.Description})
+				}
+				err = printTable(os.Stdout, []string{"ID", "NAME", "TARGET", "ENFORCEMENT", "DESCRIPTION"}, rows)
+			}
+			if err != nil {
+				log.Fatalln(err)
+			}
+			return
+		}
+
+		repos, err := loadRepositories(client, Organization, CsvFile, args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if len(ConfigurationName) > 0 {
+			// configurations belong to an organization, so the repositories are attached per owner
+			owners := map[string][]Repository{}
+			var order []string
+			for _, repo := range repos {
+				if _, ok := owners[repo.owner()]; !ok {
+					order = append(order, repo.owner())
+				}
+				owners[repo.owner()] = append(owners[repo.owner()], repo)
+			}
+
+			for _, owner := range order {
+				if err := attachNamedConfiguration(client, owner, ConfigurationName, owners[owner]); err != nil {
+					for _, repo := range owners[owner] {
+						Errors[repo.FullName] = err
+					}
+				}
+			}
+		}
+
+		// attaching runs in the background, so the repositories are polled until it is applied or the timeout is reached
+		deadline := time.Now()
+		if len(ConfigurationName) > 0 {
+			deadline = deadline.Add(ConfigurationPollTimeout)
+		}
+
+		var statuses []ConfigurationStatus
+		var pending []string
+		for _, repo := range repos {
+			if _, failed := Errors[repo.FullName]; failed {
+				continue
+			}
+			status, err := repo.waitForConfigurationStatus(client, deadline)
+			if pendingConfigurationStates[status.Status] {
+				pending = append(pending, repo.FullName)
+			}
+			if err != nil {
+				Errors[repo.FullName] = err
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+
+		if OutputFormat == "json" {
+			err = printJSON(os.Stdout, statuses)
+		} else {
+			var rows [][]string
+			for _, status := range statuses {
+				rows = append(rows, []string{status.Repository, status.Configuration, status.Status, status.Enforcement})
+			}
+			err = printTable(os.Stdout, []string{"REPOSITORY", "CONFIGURATION", "STATUS", "ENFORCEMENT"}, rows)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if len(pending) > 0 {
+			log.Printf("Repositories where the configuration is still being applied, run security-config without --name later to check them: %d\n", len(pending))
+			for _, repo := range pending {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+	},
+}
+
+func listCodeSecurityConfigurations(client Client, Organization string) ([]CodeSecurityConfiguration, error) {
+	requestPath := fmt.Sprintf("orgs/%s/code-security/configurations?per_page=100", Organization)
+	var allConfigurations []CodeSecurityConfiguration
+
+	for {
+		var configurations []CodeSecurityConfiguration
+		statusCode, nextPage, err := callApi(client, requestPath, &configurations, GET)
+		if err != nil {
+			if statusCode == 404 {
+				log.Printf("ERROR: The organization %s does not exist\n", Organization)
+			} else {
+				log.Printf("ERROR: Unable to list code security configurations for organization %s\n", Organization)
+			}
+			return allConfigurations, err
+		}
+		allConfigurations = append(allConfigurations, configurations...)
+
+		var hasNextPage bool
+		if requestPath, hasNextPage = findNextPage(nextPage); !hasNextPage {
+			break
+		}
+	}
+
+	return allConfigurations, nil
+}
+
+// attachNamedConfiguration attaches the organization configuration with the given name to the repositories in a single request.
+func attachNamedConfiguration(client Client, Organization string, name string, repos []Repository) error {
+	configurations, err := listCodeSecurityConfigurations(client, Organization)
+	if err != nil {
+		return err
+	}
+
+	for _, configuration := range configurations {
+		if strings.EqualFold(configuration.Name, name) {
+			var repositoryIDs []int64
+			for _, repo := range repos {
+				repositoryIDs = append(repositoryIDs, repo.ID)
+			}
+			return attachCodeSecurityConfiguration(client, Organization, configuration.ID, repositoryIDs)
+		}
+	}
+
+	log.Printf("ERROR: The organization %s has no code security configuration named %s\n", Organization, name)
+	return fmt.Errorf("code security configuration %s not found in organization %s", name, Organization)
+}
+
+func attachCodeSecurityConfiguration(client Client, Organization string, configurationID int64, repositoryIDs []int64) error {
+	type requestBody struct {
+		Scope                 string  `json:"scope"`
+		SelectedRepositoryIDs []int64 `json:"selected_repository_ids"`
+	}
+
+	request := requestBody{Scope: "selected", SelectedRepositoryIDs: repositoryIDs}
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		log.Printf("ERROR: Unable to marshal JSON request body\n")
+		return err
+	}
+
+	requestPath := fmt.Sprintf("orgs/%s/code-security/configurations/%d/attach", Organization, configurationID)
+	statusCode, _, err := callApi(client, requestPath, nil, POST, jsonData)
+	if statusCode == 202 {
+		log.Printf("Attaching code security configuration %d to %d repositories in organization %s\n", configurationID, len(repositoryIDs), Organization)
+		return nil
+	}
+
+	log.Printf("ERROR: Unable to attach code security configuration %d in organization %s\n", configurationID, Organization)
+	if err == nil {
+		err = fmt.Errorf("unexpected status %d attaching code security configuration %d", statusCode, configurationID)
+	}
+	return err
+}
+
+// pendingConfigurationStates are the statuses of a configuration that GitHub is still applying to the repository.
+var pendingConfigurationStates = map[string]bool{"attaching": true, "updating": true}
+
+// waitForConfigurationStatus polls the configuration status of the repository until it is no longer pending or the deadline has passed.
+func (repo *Repository) waitForConfigurationStatus(client Client, deadline time.Time) (ConfigurationStatus, error) {
+	for {
+		status, err := repo.getConfigurationStatus(client)
+		if err != nil || !pendingConfigurationStates[status.Status] {
+			return status, err
+		}
+
+		if time.Now().After(deadline) {
+			log.Printf("WARN: The code security configuration %s of repository %s is still %s\n", status.Configuration, repo.FullName, status.Status)
+			return status, nil
+		}
+
+		log.Printf("The code security configuration %s of repository %s is %s, waiting\n", status.Configuration, repo.FullName, status.Status)
+		time.Sleep(ConfigurationPollInterval)
+	}
+}
+
+func (repo *Repository) getConfigurationStatus(client Client) (ConfigurationStatus, error) {
+	var response struct {
+		Status        string                    `json:"status"`
+		Configuration CodeSecurityConfiguration `json:"configuration"`
+	}
+
+	status := ConfigurationStatus{Repository: repo.FullName}
+	requestPath := fmt.Sprintf("repos/%s/code-security-configuration", repo.FullName)
+	statusCode, _, err := callApi(client, requestPath, &response, GET)
+	if statusCode == 204 {
+		status.Status = "none"
+		return status, nil
+	} else if err != nil {
+		log.Printf("ERROR: Unable to get code security configuration for repository %s\n", repo.FullName)
+		return status, err
+	}
+
+	status.Configuration = response.Configuration.Name
+	status.Status = response.Status
+	status.Enforcement = response.Configuration.Enforcement
+	return status, nil
+}
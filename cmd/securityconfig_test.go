@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_listCodeSecurityConfigurations(t *testing.T) {
+	tests := []struct {
+		name         string
+		organization string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:         "When the organization has configurations",
+			organization: "paradisisland",
+			want:         []string{"High risk", "Legacy"},
+			wantErr:      false,
+		},
+		{
+			name:         "When the organization is invalid",
+			organization: "atotallyrealorgname",
+			want:         nil,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			configurations, err := listCodeSecurityConfigurations(client, tt.organization)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("listCodeSecurityConfigurations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var got []string
+			for _, configuration := range configurations {
+				got = append(got, configuration.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listCodeSecurityConfigurations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_attachNamedConfiguration(t *testing.T) {
+	repos := []Repository{
+		{ID: 1296269, FullName: "paradisisland/maria"},
+		{ID: 1296270, FullName: "paradisisland/rose"},
+	}
+	tests := []struct {
+		name              string
+		configurationName string
+		wantErr           bool
+	}{
+		{
+			name:              "When the configuration exists",
+			configurationName: "high risk",
+			wantErr:           false,
+		},
+		{
+			name:              "When the configuration does not exist",
+			configurationName: "Low risk",
+			wantErr:           true,
+		},
+		{
+			name:              "When the configuration cannot be attached",
+			configurationName: "Legacy",
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			if err := attachNamedConfiguration(client, "paradisisland", tt.configurationName, repos); (err != nil) != tt.wantErr {
+				t.Errorf("attachNamedConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// recordingClient records the POST requests sent through the test client.
+type recordingClient struct {
+	TestClient
+	posts  []string
+	bodies [][]byte
+}
+
+func (client *recordingClient) Request(method string, path string, body io.Reader) (*http.Response, error) {
+	if method == "POST" {
+		content, _ := io.ReadAll(body)
+		client.posts = append(client.posts, path)
+		client.bodies = append(client.bodies, content)
+	}
+	return client.TestClient.Request(method, path, nil)
+}
+
+func Test_attachNamedConfiguration_batch(t *testing.T) {
+	repos := []Repository{
+		{ID: 1296269, FullName: "paradisisland/maria"},
+		{ID: 1296270, FullName: "paradisisland/rose"},
+		{ID: 1296271, FullName: "paradisisland/shiganshima"},
+	}
+	client := &recordingClient{}
+	if err := attachNamedConfiguration(client, "paradisisland", "High risk", repos); err != nil {
+		t.Fatalf("attachNamedConfiguration() error = %v", err)
+	}
+	if len(client.posts) != 1 {
+		t.Fatalf("attachNamedConfiguration() sent %d requests, want 1", len(client.posts))
+	}
+
+	var body struct {
+		Scope                 string  `json:"scope"`
+		SelectedRepositoryIDs []int64 `json:"selected_repository_ids"`
+	}
+	if err := json.Unmarshal(client.bodies[0], &body); err != nil {
+		t.Fatalf("attachNamedConfiguration() body error = %v", err)
+	}
+	if want := []int64{1296269, 1296270, 1296271}; body.Scope != "selected" || !reflect.DeepEqual(body.SelectedRepositoryIDs, want) {
+		t.Errorf("attachNamedConfiguration() body = %v, want selected %v", body, want)
+	}
+}
+
+func TestRepository_waitForConfigurationStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "When the configuration is applied",
+			fullName: "paradisisland/maria",
+			want:     "attached",
+			wantErr:  false,
+		},
+		{
+			name:     "When the configuration is still attaching at the deadline",
+			fullName: "paradisisland/shiganshima",
+			want:     "attaching",
+			wantErr:  false,
+		},
+		{
+			name:     "When the configuration cannot be read",
+			fullName: "paradisisland/marley",
+			want:     "",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.waitForConfigurationStatus(&TestClient{}, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.waitForConfigurationStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Status != tt.want {
+				t.Errorf("Repository.waitForConfigurationStatus() = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_getConfigurationStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     ConfigurationStatus
+		wantErr  bool
+	}{
+		{
+			name:     "When a configuration is attached",
+			fullName: "paradisisland/maria",
+			want:     ConfigurationStatus{Repository: "paradisisland/maria", Configuration: "High risk", Status: "attached", Enforcement: "enforced"},
+			wantErr:  false,
+		},
+		{
+			name:     "When no configuration is attached",
+			fullName: "paradisisland/rose",
+			want:     ConfigurationStatus{Repository: "paradisisland/rose", Status: "none"},
+			wantErr:  false,
+		},
+		{
+			name:     "When the configuration cannot be read",
+			fullName: "paradisisland/marley",
+			want:     ConfigurationStatus{Repository: "paradisisland/marley"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TestClient{}
+			repo := &Repository{FullName: tt.fullName}
+			got, err := repo.getConfigurationStatus(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Repository.getConfigurationStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repository.getConfigurationStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var OutputFormat string
+var CompleteMigrations bool
+
+// PullRequestStatus is the state of a single rollout pull request.
+type PullRequestStatus struct {
+	Repository string            `json:"repository"`
+	Branch     string            `json:"branch"`
+	Number     int               `json:"number"`
+	URL        string            `json:"url"`
+	State      string            `json:"state"`
+	Review     string            `json:"review,omitempty"`
+	Mergeable  string            `json:"mergeable,omitempty"`
+	Checks     map[string]string `json:"checks,omitempty"`
+	AgeDays    int               `json:"age_days"`
+}
+
+func init() {
+	statusCmd.PersistentFlags().StringVarP(&Organization, "organization", "o", "", "specify Organisation to report rollout pull requests for")
+	statusCmd.PersistentFlags().StringVarP(&CsvFile, "csv", "c", "", "specify the location of csv file")
+	statusCmd.PersistentFlags().StringVarP(&ReportFile, "report", "r", "", "specify the path to the JSON report of a previous code-scanning run")
+	statusCmd.MarkFlagsMutuallyExclusive("csv", "organization", "report")
+	statusCmd.PersistentFlags().StringVarP(&OutputFormat, "format", "F", "table", "specify the output format: table or json")
+	statusCmd.PersistentFlags().BoolVar(&CompleteMigrations, "complete-migrations", false, "enable default setup for repositories whose migration pull request has been merged")
+	statusCmd.PersistentFlags().StringVar(&QuerySuite, "query-suite", "default", "specify the default setup query suite used to complete migrations: default or extended")
+	statusCmd.PersistentFlags().StringVarP(&LogFile, "log", "l", "gh-add-files.log", "specify the path where the log file will be saved")
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report the state of rollout pull requests",
+	Long:  "Report the state, reviews, mergeability, CodeQL checks and age of the pull requests raised by code-scanning",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//set up logging
+		if len(LogFile) <= 0 {
+			LogFile = "gh-add-files.log"
+		}
+
+		// logs go to stderr so the table or JSON output can be piped
+		logFile, err := setupLogging(LogFile, os.Stderr)
+		if err != nil {
+			panic(err)
+		}
+		defer logFile.Close()
+
+		if len(Organization) <= 0 && len(CsvFile) <= 0 && len(ReportFile) <= 0 && len(args) <= 0 {
+			log.Fatalln("ERROR: Either organization flag, csv flag or report flag must be provided")
+		} else if (len(Organization) > 0 || len(CsvFile) > 0 || len(ReportFile) > 0) && len(args) > 0 {
+			log.Fatalln("ERROR: You cannot provide both input flags and repository names as arguments")
+		}
+
+		if OutputFormat != "table" && OutputFormat != "json" {
+			log.Fatalf("ERROR: Unknown output format %s, must be table or json\n", OutputFormat)
+		} else if QuerySuite != "default" && QuerySuite != "extended" {
+			log.Fatalf("ERROR: Unknown query suite %s, must be default or extended\n", QuerySuite)
+		}
+
+		//set up github client
+		client, err := api.DefaultRESTClient()
+		if err != nil {
+			log.Fatalln("ERROR: Unable to create REST client: ", err)
+		}
+
+		var repos []Repository
+		if len(ReportFile) > 0 {
+			repos, err = loadReportRepositories(client, ReportFile)
+		} else {
+			repos, err = loadRepositories(client, Organization, CsvFile, args)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var statuses []PullRequestStatus
+		var migrated []string
+		for _, repo := range repos {
+			for _, branch := range []string{workflowBranch, migrationBranch} {
+				pullRequests, err := repo.listPullRequests(client, branch, "all")
+				if err != nil {
+					Errors[repo.FullName] = err
+					continue
+				}
+
+				for _, pullRequest := range pullRequests {
+					status, err := repo.getPullRequestStatus(client, pullRequest.Number)
+					if err != nil {
+						Errors[repo.FullName] = err
+						continue
+					}
+					statuses = append(statuses, status)
+
+					if CompleteMigrations && branch == migrationBranch {
+						enabled, err := repo.completeMigration(client, status)
+						if err != nil {
+							Errors[repo.FullName] = err
+							continue
+						}
+						if enabled {
+							migrated = append(migrated, repo.FullName)
+						}
+					}
+				}
+			}
+		}
+
+		if OutputFormat == "json" {
+			if err := printJSON(os.Stdout, statuses); err != nil {
+				log.Fatalln(err)
+			}
+		} else {
+			var rows [][]string
+			for _, status := range statuses {
+				rows = append(rows, []string{status.Repository, status.Branch, fmt.Sprintf("#%d", status.Number), status.State, status.Review, status.Mergeable, formatChecks(status.Checks), fmt.Sprintf("%dd", status.AgeDays)})
+			}
+			if err := printTable(os.Stdout, []string{"REPOSITORY", "BRANCH", "PR", "STATE", "REVIEW", "MERGEABLE", "CODEQL CHECKS", "AGE"}, rows); err != nil {
+				log.Fatalln(err)
+			}
+		}
+
+		if len(migrated) > 0 {
+			log.Printf("Repositories migrated to default setup: %d\n", len(migrated))
+			for _, repo := range migrated {
+				log.Printf("Repository: %s\n", repo)
+			}
+		}
+
+		if len(Errors) > 0 {
+			log.Printf("Repositories with errors: %d\n", len(Errors))
+			for k, v := range Errors {
+				log.Printf("Repository: %s Message: [%s]\n", k, v)
+			}
+		}
+	},
+}
+
+func loadReportRepositories(client Client, ReportFile string) ([]Repository, error) {
+	report, err := readRunReport(ReportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repository
+	for _, entry := range report.Repositories {
+		if len(entry.PullRequest) <= 0 {
+			continue
+		}
+		repo, err := getRepo(entry.FullName, client)
+		if err != nil {
+			Errors[entry.FullName] = err
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+func (repo *Repository) getPullRequestStatus(client Client, number int) (PullRequestStatus, error) {
+	pullRequest, err := repo.getPullRequest(client, number)
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+
+	status := PullRequestStatus{
+		Repository: repo.FullName,
+		Branch:     pullRequest.Head.Ref,
+		Number:     pullRequest.Number,
+		URL:        pullRequest.HTMLURL,
+		State:      pullRequest.State,
+		AgeDays:    pullRequestAge(pullRequest, time.Now()),
+	}
+	if pullRequest.MergedAt != nil {
+		status.State = "merged"
+	}
+
+	if status.State == "open" {
+		if pullRequest.Mergeable == nil {
+			status.Mergeable = "unknown"
+		} else if *pullRequest.Mergeable {
+			status.Mergeable = pullRequest.MergeableState
+		} else {
+			status.Mergeable = "conflicting"
+		}
+	}
+
+	status.Review, err = repo.getReviewState(client, number)
+	if err != nil {
+		return status, err
+	}
+
+	status.Checks, err = repo.getCodeqlCheckRuns(client, pullRequest.Head.Sha)
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// pullRequestAge is the number of days the pull request has been open, or was open before it was closed.
+func pullRequestAge(pullRequest PullRequest, now time.Time) int {
+	end := now
+	if pullRequest.ClosedAt != nil {
+		end = *pullRequest.ClosedAt
+	}
+	return int(end.Sub(pullRequest.CreatedAt).Hours() / 24)
+}
+
+func formatChecks(checks map[string]string) string {
+	if len(checks) == 0 {
+		return "none"
+	}
+
+	var names []string
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var formatted []string
+	for _, name := range names {
+		formatted = append(formatted, fmt.Sprintf("%s: %s", name, checks[name]))
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// workflowTriggers are the events a workflow can be triggered by.
+var workflowTriggers = map[string]bool{
+	"branch_protection_rule": true, "check_run": true, "check_suite": true, "create": true, "delete": true,
+	"deployment": true, "deployment_status": true, "discussion": true, "discussion_comment": true, "fork": true,
+	"gollum": true, "issue_comment": true, "issues": true, "label": true, "merge_group": true, "milestone": true,
+	"page_build": true, "public": true, "pull_request": true, "pull_request_review": true,
+	"pull_request_review_comment": true, "pull_request_target": true, "push": true, "registry_package": true,
+	"release": true, "repository_dispatch": true, "schedule": true, "status": true, "watch": true,
+	"workflow_call": true, "workflow_dispatch": true, "workflow_run": true,
+}
+
+var (
+	actionReference   = regexp.MustCompile(`^(\./\S*|docker://\S+|[\w.-]+/[\w.-]+(/[^@\s]+)?@[^@\s]+)$`)
+	workflowReference = regexp.MustCompile(`^(\./\.github/workflows/[^@\s]+\.ya?ml|[\w.-]+/[\w.-]+/\.github/workflows/[^@\s]+\.ya?ml@[^@\s]+)$`)
+	yamlErrorLine     = regexp.MustCompile(`^yaml: line (\d+): `)
+)
+
+// WorkflowError is a problem found in a workflow file and where it is.
+type WorkflowError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e WorkflowError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// validateWorkflow checks the workflow for the mistakes GitHub would reject it for, so they fail before the file is committed.
+func validateWorkflow(content []byte) error {
+	problems := unrenderedPlaceholders(content)
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		problems = append(problems, yamlError(err))
+		return workflowErrors(problems)
+	}
+	if len(document.Content) <= 0 || document.Content[0].Kind != yaml.MappingNode {
+		problems = append(problems, WorkflowError{Line: 1, Column: 1, Message: "the workflow must be a mapping"})
+		return workflowErrors(problems)
+	}
+	root := document.Content[0]
+
+	on := mappingValue(root, "on")
+	if on == nil {
+		problems = append(problems, WorkflowError{Line: root.Line, Column: root.Column, Message: "missing the on key"})
+	} else {
+		problems = append(problems, validateTriggers(on)...)
+	}
+
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil {
+		problems = append(problems, WorkflowError{Line: root.Line, Column: root.Column, Message: "missing the jobs key"})
+	} else if jobs.Kind != yaml.MappingNode || len(jobs.Content) <= 0 {
+		problems = append(problems, WorkflowError{Line: jobs.Line, Column: jobs.Column, Message: "jobs must be a mapping of at least one job"})
+	} else {
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			problems = append(problems, validateJob(jobs.Content[i], jobs.Content[i+1])...)
+		}
+	}
+
+	return workflowErrors(problems)
+}
+
+// yamlError turns a YAML parse error into a WorkflowError at the line it reports.
+func yamlError(err error) WorkflowError {
+	line, message := 0, strings.TrimPrefix(err.Error(), "yaml: ")
+	if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+		message = strings.TrimPrefix(err.Error(), match[0])
+	}
+	return WorkflowError{Line: line, Message: message}
+}
+
+// unrenderedPlaceholders finds template placeholders that were not replaced, skipping ${{ }} expressions.
+func unrenderedPlaceholders(content []byte) []WorkflowError {
+	var problems []WorkflowError
+	for number, line := range strings.Split(string(content), "\n") {
+		for offset := 0; ; {
+			index := strings.Index(line[offset:], "{{")
+			if index < 0 {
+				break
+			}
+			column := offset + index
+			if column <= 0 || line[column-1] != '$' {
+				placeholder := line[column:]
+				if end := strings.Index(placeholder, "}}"); end >= 0 {
+					placeholder = placeholder[:end+2]
+				}
+				problems = append(problems, WorkflowError{Line: number + 1, Column: column + 1, Message: fmt.Sprintf("unrendered template placeholder %s", placeholder)})
+			}
+			offset = column + 2
+		}
+	}
+	return problems
+}
+
+func validateTriggers(on *yaml.Node) []WorkflowError {
+	var triggers []*yaml.Node
+	switch on.Kind {
+	case yaml.ScalarNode:
+		if len(on.Value) > 0 {
+			triggers = append(triggers, on)
+		}
+	case yaml.SequenceNode:
+		triggers = on.Content
+	case yaml.MappingNode:
+		for i := 0; i < len(on.Content); i += 2 {
+			triggers = append(triggers, on.Content[i])
+		}
+	}
+	if len(triggers) <= 0 {
+		return []WorkflowError{{Line: on.Line, Column: on.Column, Message: "on must list at least one trigger"}}
+	}
+
+	var problems []WorkflowError
+	for _, trigger := range triggers {
+		if trigger.Kind != yaml.ScalarNode || !workflowTriggers[trigger.Value] {
+			problems = append(problems, WorkflowError{Line: trigger.Line, Column: trigger.Column, Message: fmt.Sprintf("unknown trigger %s", trigger.Value)})
+		}
+	}
+	return problems
+}
+
+func validateJob(name *yaml.Node, job *yaml.Node) []WorkflowError {
+	if job.Kind != yaml.MappingNode {
+		return []WorkflowError{{Line: job.Line, Column: job.Column, Message: fmt.Sprintf("job %s must be a mapping", name.Value)}}
+	}
+
+	var problems []WorkflowError
+	if uses := mappingValue(job, "uses"); uses != nil {
+		if !workflowReference.MatchString(uses.Value) {
+			problems = append(problems, WorkflowError{Line: uses.Line, Column: uses.Column, Message: fmt.Sprintf("malformed reusable workflow reference %s", uses.Value)})
+		}
+		return problems
+	}
+
+	if mappingValue(job, "runs-on") == nil {
+		problems = append(problems, WorkflowError{Line: name.Line, Column: name.Column, Message: fmt.Sprintf("job %s is missing runs-on", name.Value)})
+	}
+	steps := mappingValue(job, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		problems = append(problems, WorkflowError{Line: name.Line, Column: name.Column, Message: fmt.Sprintf("job %s is missing steps", name.Value)})
+		return problems
+	}
+	for _, step := range steps.Content {
+		uses := mappingValue(step, "uses")
+		if uses == nil {
+			if mappingValue(step, "run") == nil {
+				problems = append(problems, WorkflowError{Line: step.Line, Column: step.Column, Message: "step must have uses or run"})
+			}
+			continue
+		}
+		if !actionReference.MatchString(uses.Value) {
+			problems = append(problems, WorkflowError{Line: uses.Line, Column: uses.Column, Message: fmt.Sprintf("malformed action reference %s", uses.Value)})
+		}
+	}
+	return problems
+}
+
+func workflowErrors(problems []WorkflowError) error {
+	if len(problems) <= 0 {
+		return nil
+	}
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// validateCodeqlWorkflowFile validates the workflow that is about to be committed to the repository.
+func (repo *Repository) validateCodeqlWorkflowFile(workflowFile []byte) error {
+	if err := validateWorkflow(workflowFile); err != nil {
+		log.Printf("ERROR: The CodeQL workflow file for repository %s is invalid: %s\n", repo.FullName, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func Test_validateWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "When the workflow is valid",
+			content: renderedWorkflow,
+			wantErr: "",
+		},
+		{
+			name:    "When the workflow calls a reusable workflow",
+			content: "on: [ push, workflow_dispatch ]\njobs:\n  codeql:\n    uses: my-org/security/.github/workflows/codeql.yml@v1\n",
+			wantErr: "",
+		},
+		{
+			name:    "When a placeholder was not rendered",
+			content: "on:\n  push:\n    branches: [ {{ .Branch }} ]\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ matrix.language }}\n",
+			wantErr: "line 3, column 17: unrendered template placeholder {{ .Branch }}",
+		},
+		{
+			name:    "When the workflow is not valid YAML",
+			content: "on: push\njobs:\n  analyze:\n    runs-on: [ ubuntu-latest\n",
+			wantErr: "line 3: did not find expected ',' or ']'",
+		},
+		{
+			name:    "When the workflow has no jobs and an unknown trigger",
+			content: "name: CodeQL\non:\n  pushed:\n",
+			wantErr: "line 3, column 3: unknown trigger pushed; line 1, column 1: missing the jobs key",
+		},
+		{
+			name:    "When the references are malformed",
+			content: "on: push\njobs:\n  analyze:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout\n      - name: Build\n  central:\n    uses: my-org/security/codeql.yml@v1\n",
+			wantErr: "line 6, column 15: malformed action reference actions/checkout; line 7, column 9: step must have uses or run; line 9, column 11: malformed reusable workflow reference my-org/security/codeql.yml@v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkflow([]byte(tt.content))
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.wantErr {
+				t.Errorf("validateWorkflow() error = %v, want %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateWorkflow_examples(t *testing.T) {
+	repo := &Repository{FullName: "paradisisland/maria", DefaultBranch: "main"}
+	for _, template := range []string{"../examples/codeql.yml", "../examples/codeql-template.yml", "../examples/dependency-review-template.yml"} {
+		content, err := repo.renderTemplateFile(template, dependencyReviewValues())
+		if err != nil {
+			t.Fatalf("Repository.renderTemplateFile() error = %v", err)
+		}
+		if err := validateWorkflow(content); err != nil {
+			t.Errorf("validateWorkflow() %s error = %v", template, err)
+		}
+	}
+}